@@ -7,10 +7,15 @@ import (
 	"strings"
 	"time"
 
+	"example.com/k8s-audit/internal/advisories"
 	"example.com/k8s-audit/internal/audit"
+	"example.com/k8s-audit/internal/cosign"
+	"example.com/k8s-audit/internal/hostcheck"
 	"example.com/k8s-audit/internal/k8s"
 	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/registry"
 	"example.com/k8s-audit/internal/report"
+	"example.com/k8s-audit/internal/vulnreport"
 )
 
 func main() {
@@ -21,6 +26,21 @@ func main() {
 		thresholdStr string
 		probeIMDS    bool
 		includeKube  bool
+		istioRoot    string
+		registries   string
+		vulnReports  string
+		cosignKeys   string
+		sigRegistry  string
+		plainHTTP    bool
+		readSecrets  bool
+		readKubelet  bool
+		mode         string
+		hostRoot     string
+		nodeName     string
+		nodeKubelet  string
+		nodeInterval time.Duration
+		nodeAgents   string
+		advisoryFile string
 	)
 
 	flag.StringVar(&outPath, "out", "", "output JSON file path (default: stdout)")
@@ -29,6 +49,21 @@ func main() {
 	flag.StringVar(&thresholdStr, "fail-on", "HIGH", "exit with code 2 if findings >= this severity (LOW|MEDIUM|HIGH|CRITICAL)")
 	flag.BoolVar(&probeIMDS, "probe-imds", false, "active probe to 169.254.169.254 from this Pod")
 	flag.BoolVar(&includeKube, "include-kube-system", false, "include kube-system namespace")
+	flag.StringVar(&istioRoot, "istio-root-namespace", "istio-system", "Istio root namespace for mesh-wide policies")
+	flag.StringVar(&registries, "allowed-registries", "", "comma-separated registry allowlist, e.g. registry.local,ghcr.io/acme (default: no check)")
+	flag.StringVar(&vulnReports, "vuln-reports", "", "comma-separated Trivy/Grype JSON or CycloneDX/SPDX files or directories to correlate with running images")
+	flag.StringVar(&cosignKeys, "cosign-keys", "", "comma-separated cosign public key files; enables image signature verification")
+	flag.StringVar(&sigRegistry, "signature-registry", "", "registry host[:port] to fetch images and signatures from instead of the image's own registry")
+	flag.BoolVar(&plainHTTP, "registry-plain-http", false, "use plain HTTP for registry requests (local registry)")
+	flag.BoolVar(&readSecrets, "analyze-secrets", false, "read and analyse Secret contents (values are never reported; needs rbac_audit_secrets.yaml)")
+	flag.BoolVar(&readKubelet, "kubelet-config", false, "read kubelet configuration via nodes/proxy configz (needs rbac_audit_kubelet.yaml)")
+	flag.StringVar(&mode, "mode", "cluster", "cluster: audit through the API server; node: host checks from a DaemonSet (see node_audit.yaml)")
+	flag.StringVar(&hostRoot, "host-root", "/host", "node mode: path where the host root filesystem is mounted")
+	flag.StringVar(&nodeName, "node-name", os.Getenv("NODE_NAME"), "node mode: node name for findings (default: $NODE_NAME or the host's hostname)")
+	flag.StringVar(&nodeKubelet, "node-kubelet-config", "", "node mode: kubelet config file on the host (default /var/lib/kubelet/config.yaml)")
+	flag.DurationVar(&nodeInterval, "node-interval", 0, "node mode: repeat the checks at this interval instead of exiting")
+	flag.StringVar(&nodeAgents, "node-agents", "", "cluster mode: merge reports of node agent pods, as namespace/labelselector (e.g. audit/app=k8s-audit-node)")
+	flag.StringVar(&advisoryFile, "advisories", "", "Kubernetes advisory database JSON to use instead of the embedded one")
 	flag.Parse()
 
 	threshold, err := model.ParseSeverity(thresholdStr)
@@ -36,6 +71,51 @@ func main() {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(2)
 	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "text" && format != "json" {
+		fmt.Fprintln(os.Stderr, "unknown format:", format)
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "cluster":
+	case "node":
+		os.Exit(runNodeAgent(hostcheck.Options{
+			Root:          hostRoot,
+			Node:          hostNodeName(nodeName, hostRoot),
+			KubeletConfig: nodeKubelet,
+		}, nodeInterval, format, outPath, threshold))
+	default:
+		fmt.Fprintln(os.Stderr, "unknown mode:", mode)
+		os.Exit(2)
+	}
+
+	advisoryDB, err := advisories.Load(advisoryFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load advisories:", err)
+		os.Exit(2)
+	}
+
+	var vulnIndex *vulnreport.Index
+	if vulnReports != "" {
+		if vulnIndex, err = vulnreport.Load(splitList(vulnReports)); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load vulnerability reports:", err)
+			os.Exit(2)
+		}
+	}
+
+	var sigVerifier *cosign.Verifier
+	if cosignKeys != "" {
+		keys, err := cosign.LoadKeys(splitList(cosignKeys))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load cosign keys:", err)
+			os.Exit(2)
+		}
+		sigVerifier = &cosign.Verifier{
+			Registry: registry.New(registry.Options{Mirror: sigRegistry, PlainHTTP: plainHTTP}),
+			Keys:     keys,
+		}
+	}
 
 	client, err := k8s.NewInClusterClient()
 	if err != nil {
@@ -51,6 +131,7 @@ func main() {
 		fmt.Fprintln(os.Stderr, "failed to list namespaces:", err)
 		os.Exit(2)
 	}
+	allNamespaces := namespaces
 	if nsFilter != "" {
 		filtered := []k8s.Namespace{}
 		for _, ns := range namespaces {
@@ -82,10 +163,12 @@ func main() {
 	}
 
 	pods, err := client.ListPodsAll()
+	podsListed := err == nil
 	if err != nil {
 		notes["pods"] = "cannot list pods: " + err.Error()
 		pods = nil
 	}
+	allPods := pods
 	if len(namespaces) > 0 {
 		allowed := map[string]struct{}{}
 		for _, ns := range namespaces {
@@ -137,33 +220,196 @@ func main() {
 		ing = nil
 	}
 
-	if _, err := client.ListNodes(); err != nil {
+	var cni audit.CNIPolicies
+	if cni.Cilium, err = client.ListCiliumNetworkPolicies(); err != nil {
+		notes["ciliumnetworkpolicies"] = "cannot list ciliumnetworkpolicies: " + err.Error()
+	}
+	if cni.CiliumClusterwide, err = client.ListCiliumClusterwideNetworkPolicies(); err != nil {
+		notes["ciliumclusterwidenetworkpolicies"] = "cannot list ciliumclusterwidenetworkpolicies: " + err.Error()
+	}
+	if cni.Calico, err = client.ListCalicoNetworkPolicies(); err != nil {
+		notes["calico-networkpolicies"] = "cannot list calico networkpolicies: " + err.Error()
+	}
+	if cni.CalicoGlobal, err = client.ListCalicoGlobalNetworkPolicies(); err != nil {
+		notes["calico-globalnetworkpolicies"] = "cannot list calico globalnetworkpolicies: " + err.Error()
+	}
+
+	cms, err := client.ListConfigMapsAll()
+	if err != nil {
+		notes["configmaps"] = "cannot list configmaps: " + err.Error()
+		cms = nil
+	}
+
+	limits := audit.ResourceInputs{Namespaces: namespaces, Pods: pods, Listed: true}
+	if limits.LimitRanges, err = client.ListLimitRangesAll(); err != nil {
+		notes["limitranges"] = "cannot list limitranges: " + err.Error()
+		limits.Listed = false
+	}
+	if limits.ResourceQuotas, err = client.ListResourceQuotasAll(); err != nil {
+		notes["resourcequotas"] = "cannot list resourcequotas: " + err.Error()
+		limits.Listed = false
+	}
+
+	var secrets []k8s.Secret
+	if readSecrets {
+		if secrets, err = client.ListSecretsAll(); err != nil {
+			notes["secrets"] = "cannot list secrets: " + err.Error()
+		}
+	}
+
+	var gw audit.GatewayAPI
+	if gw.Gateways, err = client.ListGateways(); err != nil {
+		notes["gateways"] = "cannot list gateways: " + err.Error()
+	}
+	if gw.HTTPRoutes, err = client.ListHTTPRoutes(); err != nil {
+		notes["httproutes"] = "cannot list httproutes: " + err.Error()
+	}
+	if gw.TLSRoutes, err = client.ListTLSRoutes(); err != nil {
+		notes["tlsroutes"] = "cannot list tlsroutes: " + err.Error()
+	}
+	if gw.ReferenceGrants, err = client.ListReferenceGrants(); err != nil {
+		notes["referencegrants"] = "cannot list referencegrants: " + err.Error()
+	}
+
+	mesh := audit.MeshInputs{
+		IstioInstalled:     client.IstioInstalled(),
+		IstioRootNamespace: istioRoot,
+		Pods:               allPods,
+		LinkerdInstalled:   client.LinkerdInstalled(),
+	}
+	if mesh.IstioInstalled {
+		if mesh.PeerAuthentications, err = client.ListPeerAuthentications(); err != nil {
+			notes["peerauthentications"] = "cannot list peerauthentications: " + err.Error()
+		}
+		if mesh.IstioAuthorizationPolicies, err = client.ListIstioAuthorizationPolicies(); err != nil {
+			notes["istio-authorizationpolicies"] = "cannot list istio authorizationpolicies: " + err.Error()
+		}
+	}
+	if mesh.LinkerdInstalled {
+		if mesh.LinkerdServers, err = client.ListLinkerdServers(); err != nil {
+			notes["linkerd-servers"] = "cannot list linkerd servers: " + err.Error()
+		}
+		if mesh.LinkerdAuthorizationPolicies, err = client.ListLinkerdAuthorizationPolicies(); err != nil {
+			notes["linkerd-authorizationpolicies"] = "cannot list linkerd authorizationpolicies: " + err.Error()
+		}
+		if mesh.LinkerdServerAuthorizations, err = client.ListLinkerdServerAuthorizations(); err != nil {
+			notes["linkerd-serverauthorizations"] = "cannot list linkerd serverauthorizations: " + err.Error()
+		}
+		if mesh.LinkerdNetworkAuthentications, err = client.ListLinkerdNetworkAuthentications(); err != nil {
+			notes["linkerd-networkauthentications"] = "cannot list linkerd networkauthentications: " + err.Error()
+		}
+		if mesh.LinkerdMeshTLSAuthentications, err = client.ListLinkerdMeshTLSAuthentications(); err != nil {
+			notes["linkerd-meshtlsauthentications"] = "cannot list linkerd meshtlsauthentications: " + err.Error()
+		}
+	}
+
+	nodes, err := client.ListNodes()
+	if err != nil {
 		notes["nodes"] = "cannot list nodes (ok for read-only mode): " + err.Error()
+		nodes = nil
+	}
+
+	var kubeletConfigs map[string]*k8s.KubeletConfiguration
+	if readKubelet {
+		kubeletConfigs = map[string]*k8s.KubeletConfiguration{}
+		var failed []string
+		for _, n := range nodes {
+			kc, err := client.KubeletConfig(n.Metadata.Name)
+			if err != nil {
+				failed = append(failed, err.Error())
+				continue
+			}
+			kubeletConfigs[n.Metadata.Name] = kc
+		}
+		if len(failed) > 0 {
+			notes["kubelet-configz"] = fmt.Sprintf("cannot read kubelet configz on %d of %d nodes: %s", len(failed), len(nodes), failed[0])
+		}
 	}
 
+	serverVersion := client.ServerVersion()
+
 	findings := []model.Finding{}
 	findings = append(findings, audit.DetectNamespacePSS(namespaces)...)
 	findings = append(findings, audit.DetectPodMisconfigs(pods, saIndex)...)
+	findings = append(findings, audit.DetectPlaintextCredentials(pods)...)
+	findings = append(findings, audit.DetectResourceGovernance(limits)...)
+	findings = append(findings, audit.DetectControlPlaneScheduling(pods, nodes)...)
+	findings = append(findings, audit.DetectNodePosture(nodes, serverVersion)...)
+	findings = append(findings, audit.DetectKubernetesCVEs(advisoryDB, serverVersion, nodes)...)
+	findings = append(findings, audit.DetectKubeletConfig(kubeletConfigs)...)
+	findings = append(findings, audit.DetectControlPlaneFlags(allPods)...)
+	findings = append(findings, audit.DetectImages(pods, splitList(registries))...)
+	findings = append(findings, audit.DetectVulnerableAddons(audit.AddonInputs{
+		Pods:       allPods,
+		Services:   svcs,
+		Ingresses:  ing,
+		ConfigMaps: cms,
+		Advisories: advisoryDB,
+	})...)
+	var rbac audit.EffectiveRBAC
 	if len(sas) > 0 || len(rbs) > 0 || len(crbs) > 0 {
-		e := audit.BuildEffectiveRBAC(sas, roles, clusterRoles, rbs, crbs)
-		findings = append(findings, audit.DetectRBAC(e)...)
+		rbac = audit.BuildEffectiveRBAC(sas, roles, clusterRoles, rbs, crbs)
+		findings = append(findings, audit.DetectRBAC(rbac)...)
 		findings = append(findings, audit.DetectClusterRolesDirect(clusterRoles)...)
 	}
-	findings = append(findings, audit.DetectNetwork(namespaces, nps, svcs, ing)...)
+	findings = append(findings, audit.DetectNetwork(audit.NetworkInputs{
+		Namespaces:      namespaces,
+		AllNamespaces:   allNamespaces,
+		Pods:            allPods,
+		PodsListed:      podsListed,
+		NetworkPolicies: nps,
+		Services:        svcs,
+		Ingresses:       ing,
+		Gateway:         gw,
+		CNI:             cni,
+	})...)
+	findings = append(findings, audit.DetectServiceMesh(namespaces, svcs, mesh)...)
+	findings = append(findings, audit.DetectExposedWorkloads(pods, svcs, ing, saIndex, rbac)...)
+	findings = append(findings, audit.DetectConfigMapSecrets(namespaces, cms, pods, podsListed, rbac)...)
+	findings = append(findings, audit.DetectSecretContents(namespaces, secrets, sas)...)
+	secretFindings, secretExposure := audit.DetectSecretBlastRadius(audit.SecretGraphInputs{
+		Namespaces:      namespaces,
+		Secrets:         secrets,
+		Pods:            allPods,
+		PodsListed:      podsListed,
+		ServiceAccounts: sas,
+		Ingresses:       ing,
+		Gateways:        gw.Gateways,
+		RBAC:            rbac,
+	})
+	findings = append(findings, secretFindings...)
+	if secrets != nil && !podsListed {
+		notes["unused-secrets"] = "K8S-SEC-007 skipped: pods could not be listed"
+	}
+	findings = append(findings, audit.DetectImageSignatures(pods, sigVerifier)...)
+	findings = append(findings, audit.DetectImageVulns(pods, svcs, ing, saIndex, rbac, vulnIndex)...)
 	findings = append(findings, audit.DetectIMDSProbe(probeIMDS)...)
+	if nodeAgents != "" {
+		nodeFindings, note := collectNodeReports(client, nodeAgents, nodes)
+		findings = append(findings, nodeFindings...)
+		if note != "" {
+			notes["node-agents"] = note
+		}
+	}
 
 	rep := model.Report{
 		Cluster: model.ClusterMeta{
-			ServerVersion: client.ServerVersion(),
+			ServerVersion: serverVersion,
 			APIServer:     client.BaseURL(),
 		},
 		GeneratedAt: time.Now().UTC(),
 		Summary:     report.Summarize(findings),
 		Findings:    findings,
 		Notes:       notes,
+
+		SecretExposure: secretExposure,
 	}
 
-	format = strings.ToLower(strings.TrimSpace(format))
+	os.Exit(emit(rep, format, outPath, threshold))
+}
+
+// emit prints or writes the report and returns the exit code: 2 if a finding reaches threshold.
+func emit(rep model.Report, format, outPath string, threshold model.Severity) int {
 	switch format {
 	case "text":
 		report.PrintTextReport(rep)
@@ -173,21 +419,27 @@ func main() {
 	case "json":
 		if err := report.WriteJSON(outPath, rep); err != nil {
 			fmt.Fprintln(os.Stderr, "write json:", err)
-			os.Exit(2)
+			return 2
 		}
 	default:
 		fmt.Fprintln(os.Stderr, "unknown format:", format)
-		os.Exit(2)
+		return 2
 	}
 
-	fail := false
-	for _, f := range findings {
+	for _, f := range rep.Findings {
 		if model.SeverityRank(f.Severity) >= model.SeverityRank(threshold) {
-			fail = true
-			break
+			return 2
 		}
 	}
-	if fail {
-		os.Exit(2)
+	return 0
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
+	return out
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"example.com/k8s-audit/internal/hostcheck"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/report"
+)
+
+// runNodeAgent checks the host mounted at opts.Root. With interval > 0 it repeats the
+// checks forever, so the DaemonSet pod log always ends with a current report.
+func runNodeAgent(opts hostcheck.Options, interval time.Duration, format, outPath string, threshold model.Severity) int {
+	for {
+		hostFindings, err := hostcheck.Run(opts)
+		findings := append([]model.Finding{}, hostFindings...)
+		rep := model.Report{
+			Node:        opts.Node,
+			GeneratedAt: time.Now().UTC(),
+			Summary:     report.Summarize(findings),
+			Findings:    findings,
+		}
+		if err != nil {
+			rep.Notes = map[string]string{"kubelet-config": "kubelet checks skipped: " + err.Error()}
+		}
+		code := emit(rep, format, outPath, threshold)
+		if interval <= 0 {
+			return code
+		}
+		time.Sleep(interval)
+	}
+}
+
+// hostNodeName falls back to the host's /etc/hostname when NODE_NAME is not set.
+func hostNodeName(name, root string) string {
+	if name != "" {
+		return name
+	}
+	if b, err := os.ReadFile(filepath.Join(root, "etc", "hostname")); err == nil {
+		if h := strings.TrimSpace(string(b)); h != "" {
+			return h
+		}
+	}
+	h, _ := os.Hostname()
+	return h
+}
+
+// collectNodeReports merges the latest report from each node agent pod selected by
+// "namespace/labelselector". A report is accepted only for the node the pod runs on.
+// The returned note lists agents and nodes without a report and the agents' own notes.
+func collectNodeReports(client *k8s.Client, spec string, nodes []k8s.Node) ([]model.Finding, string) {
+	ns, selector, ok := strings.Cut(spec, "/")
+	if !ok || ns == "" {
+		return nil, fmt.Sprintf("invalid -node-agents %q, want namespace/labelselector", spec)
+	}
+	pods, err := client.ListPods(ns, selector)
+	if err != nil {
+		return nil, "cannot list node agent pods: " + err.Error()
+	}
+
+	var out []model.Finding
+	var failed, agentNotes []string
+	reported := map[string]bool{}
+	for _, p := range pods {
+		if p.Status.Phase != "Running" {
+			failed = append(failed, fmt.Sprintf("%s: phase %s", p.Metadata.Name, p.Status.Phase))
+			continue
+		}
+		log, err := client.PodLog(ns, p.Metadata.Name)
+		if err != nil {
+			failed = append(failed, p.Metadata.Name+": "+err.Error())
+			continue
+		}
+		rep, ok := lastReport(log)
+		if !ok {
+			failed = append(failed, p.Metadata.Name+": no JSON report in log (agent must run with -format json)")
+			continue
+		}
+		if rep.Node != p.Spec.NodeName {
+			failed = append(failed, fmt.Sprintf("%s: report for node %q, but the pod runs on %q (rejected)", p.Metadata.Name, rep.Node, p.Spec.NodeName))
+			continue
+		}
+		reported[rep.Node] = true
+		for _, f := range rep.Findings {
+			f.Resource = model.ResourceRef{Kind: "Node", Name: p.Spec.NodeName}
+			out = append(out, f)
+		}
+		keys := make([]string, 0, len(rep.Notes))
+		for k := range rep.Notes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			agentNotes = append(agentNotes, fmt.Sprintf("%s %s: %s", rep.Node, k, rep.Notes[k]))
+		}
+	}
+
+	var missing []string
+	for _, n := range nodes {
+		if !reported[n.Metadata.Name] {
+			missing = append(missing, n.Metadata.Name)
+		}
+	}
+	var note []string
+	if len(failed) > 0 {
+		note = append(note, fmt.Sprintf("%d of %d agents failed: %s", len(failed), len(pods), strings.Join(failed, "; ")))
+	}
+	if len(missing) > 0 {
+		note = append(note, fmt.Sprintf("no node report for %d of %d nodes: %s", len(missing), len(nodes), strings.Join(missing, ", ")))
+	}
+	note = append(note, agentNotes...)
+	return out, strings.Join(note, "; ")
+}
+
+// lastReport finds the last complete JSON report in an agent log. Reports are written
+// indented, so each starts with a "{" line; stderr output may be interleaved.
+func lastReport(log []byte) (model.Report, bool) {
+	lines := bytes.SplitAfter(log, []byte("\n"))
+	offset := make([]int, len(lines))
+	pos := 0
+	for i, l := range lines {
+		offset[i] = pos
+		pos += len(l)
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if string(bytes.TrimSpace(lines[i])) != "{" {
+			continue
+		}
+		var rep model.Report
+		if err := json.NewDecoder(bytes.NewReader(log[offset[i]:])).Decode(&rep); err == nil && rep.Node != "" {
+			return rep, true
+		}
+	}
+	return model.Report{}, false
+}
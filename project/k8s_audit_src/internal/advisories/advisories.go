@@ -0,0 +1,79 @@
+// Package advisories holds an offline database of known vulnerabilities in
+// Kubernetes components and common add-ons. A copy is embedded in the binary;
+// a newer file can be passed with -advisories without rebuilding.
+package advisories
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed kubernetes.json
+var embedded []byte
+
+// Advisory is a vulnerability in one or more Kubernetes components or in an add-on.
+type Advisory struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"` // related CVEs fixed in the same releases
+	// Components: kube-apiserver, kube-controller-manager, kubelet, kube-proxy;
+	// for add-ons the add-on name, e.g. ingress-nginx.
+	Components []string `json:"components"`
+	// Images identifies an add-on by image repository path (without registry, so
+	// mirrors match), e.g. ingress-nginx/controller.
+	Images  []string `json:"images,omitempty"`
+	OS      string   `json:"os,omitempty"` // only nodes with this operating system, e.g. windows
+	CVSS    float64  `json:"cvss"`
+	Summary string   `json:"summary"`
+	// Introduced is the first affected version ("" if all earlier versions are affected).
+	Introduced string `json:"introduced,omitempty"`
+	// Fixed lists the first fixed patch release of each supported minor branch.
+	// Versions of unlisted branches below the highest fix are affected.
+	Fixed []string `json:"fixed"`
+}
+
+// DB is the advisory database.
+type DB struct {
+	Updated    string     `json:"updated"`
+	Kubernetes []Advisory `json:"kubernetes"`
+	Addons     []Advisory `json:"addons"`
+	Source     string     `json:"-"` // "embedded" or the file path
+}
+
+// Load reads the database from path, or the embedded copy if path is empty.
+func Load(path string) (*DB, error) {
+	b, source := embedded, "embedded"
+	if path != "" {
+		var err error
+		if b, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+		source = path
+	}
+	db, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	db.Source = source
+	return db, nil
+}
+
+// Parse decodes and validates a database.
+func Parse(b []byte) (*DB, error) {
+	var db DB
+	if err := json.Unmarshal(b, &db); err != nil {
+		return nil, err
+	}
+	for i, a := range db.Kubernetes {
+		if a.ID == "" || len(a.Components) == 0 || len(a.Fixed) == 0 {
+			return nil, fmt.Errorf("kubernetes advisory #%d (%q): id, components and fixed are required", i, a.ID)
+		}
+	}
+	for i, a := range db.Addons {
+		if a.ID == "" || len(a.Components) == 0 || len(a.Images) == 0 || len(a.Fixed) == 0 {
+			return nil, fmt.Errorf("addon advisory #%d (%q): id, components, images and fixed are required", i, a.ID)
+		}
+	}
+	return &db, nil
+}
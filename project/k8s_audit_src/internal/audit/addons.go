@@ -0,0 +1,231 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/advisories"
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Add-ons recognised by image for deployment checks; versions are matched from the advisory database.
+var (
+	dashboardImages     = []string{"kubernetesui/dashboard", "kubernetesui/dashboard-api", "kubernetes-dashboard-amd64", "kubernetes-dashboard-arm64"}
+	tillerImages        = []string{"kubernetes-helm/tiller", "helm/tiller"}
+	metricsServerImages = []string{"metrics-server/metrics-server", "metrics-server-amd64", "metrics-server"}
+	ingressNginxImages  = []string{"ingress-nginx/controller", "ingress-nginx/controller-chroot", "kubernetes-ingress-controller/nginx-ingress-controller"}
+)
+
+// AddonInputs are the objects needed to identify add-ons and their configuration.
+type AddonInputs struct {
+	Pods       []k8s.Pod // all namespaces: add-ons usually live in kube-system
+	Services   []k8s.Service
+	Ingresses  []k8s.Ingress
+	ConfigMaps []k8s.ConfigMap
+	Advisories *advisories.DB
+}
+
+type addonWorkload struct {
+	ref     model.ResourceRef
+	image   string
+	pods    []string
+	exposed bool
+	matches []advisoryMatch
+	risks   []string
+	sev     model.Severity
+}
+
+// DetectVulnerableAddons identifies common add-ons by image and reports known-vulnerable
+// versions (K8S-ADDON-001) and risky deployment settings (K8S-ADDON-002).
+func DetectVulnerableAddons(in AddonInputs) []model.Finding {
+	exposed := exposedPods(in.Pods, in.Services, in.Ingresses)
+	cms := map[string]k8s.ConfigMap{}
+	for _, cm := range in.ConfigMaps {
+		cms[cm.Metadata.Namespace+"/"+cm.Metadata.Name] = cm
+	}
+
+	byKey := map[string]*addonWorkload{}
+	var keys []string
+	for _, p := range in.Pods {
+		pk := p.Metadata.Namespace + "/" + p.Metadata.Name
+		owner := podController(p)
+		_, isExposed := exposed[pk]
+		for _, c := range append(append([]k8s.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+			ref := imageref.Parse(c.Image)
+			v, versioned := parseVersion(ref.Tag)
+			var matches []advisoryMatch
+			if versioned && in.Advisories != nil {
+				matches = matchAddonAdvisories(in.Advisories, ref.Repository, v)
+			}
+			risks, sev := addonRisks(p, c, ref, cms, isExposed)
+			if len(matches) == 0 && len(risks) == 0 {
+				continue
+			}
+			key := owner.Kind + "/" + owner.Namespace + "/" + owner.Name + "|" + c.Image
+			w, ok := byKey[key]
+			if !ok {
+				w = &addonWorkload{ref: owner, image: c.Image, matches: matches}
+				byKey[key] = w
+				keys = append(keys, key)
+			}
+			w.pods = append(w.pods, pk)
+			w.exposed = w.exposed || isExposed
+			w.risks = append(w.risks, risks...)
+			if model.SeverityRank(sev) > model.SeverityRank(w.sev) {
+				w.sev = sev
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var out []model.Finding
+	for _, k := range keys {
+		w := byKey[k]
+		pods := strings.Join(uniqStrings(w.pods), ",")
+		if len(w.matches) > 0 {
+			list, upgradeTo := describeMatches(w.matches)
+			sev := cvssSeverity(w.matches[0].adv.CVSS)
+			ev := fmt.Sprintf("image=%q: %s; pods: %s", w.image, list, pods)
+			if w.exposed {
+				sev = raiseSeverity(sev)
+				ev += "; internet-exposed"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ADDON-001",
+				Severity:       sev,
+				Resource:       w.ref,
+				Title:          "Уязвимая версия дополнения кластера",
+				Evidence:       truncateEvidence(ev, 1024),
+				Risk:           "Для этих версий есть публичные эксплойты; дополнения обычно имеют широкие права в кластере, и их компрометация раскрывает Secret или весь кластер",
+				Recommendation: fmt.Sprintf("Обновить %s до %s или новее, либо удалить неиспользуемое дополнение", w.matches[0].component, upgradeTo),
+			})
+		}
+		if risks := uniqStrings(w.risks); len(risks) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ADDON-002",
+				Severity:       w.sev,
+				Resource:       w.ref,
+				Title:          "Опасная конфигурация дополнения кластера",
+				Evidence:       truncateEvidence(fmt.Sprintf("image=%q: %s; pods: %s", w.image, strings.Join(risks, "; "), pods), 1024),
+				Risk:           "Настройки дополнения открывают его права (часто уровня cluster-admin или чтения всех Secret) без аутентификации или любому, кто создает Ingress",
+				Recommendation: "Исправить конфигурацию дополнения или удалить его, если оно не используется",
+			})
+		}
+	}
+	return out
+}
+
+func matchAddonAdvisories(db *advisories.DB, repository string, v [3]int) []advisoryMatch {
+	var out []advisoryMatch
+	for _, a := range db.Addons {
+		if !repositoryMatches(repository, a.Images...) {
+			continue
+		}
+		if fixed, ok := affectedVersion(a, v); ok {
+			out = append(out, advisoryMatch{adv: a, component: a.Components[0], fixed: fixed})
+		}
+	}
+	return out
+}
+
+// repositoryMatches compares on path boundaries so registry mirrors and prefixes still match.
+func repositoryMatches(repository string, images ...string) bool {
+	for _, img := range images {
+		if repository == img || strings.HasSuffix(repository, "/"+img) {
+			return true
+		}
+	}
+	return false
+}
+
+// addonRisks checks deployment settings of known add-ons.
+func addonRisks(p k8s.Pod, c k8s.Container, ref imageref.Ref, cms map[string]k8s.ConfigMap, exposed bool) ([]string, model.Severity) {
+	flags := parseFlags(append(append([]string{}, c.Command...), c.Args...))
+	var risks []string
+	sev := model.Severity("")
+	add := func(s model.Severity, risk string) {
+		risks = append(risks, risk)
+		if model.SeverityRank(s) > model.SeverityRank(sev) {
+			sev = s
+		}
+	}
+
+	switch {
+	case repositoryMatches(ref.Repository, dashboardImages...):
+		if flags["enable-skip-login"] == "true" {
+			s := model.SeverityHigh
+			if exposed {
+				s = model.SeverityCritical
+			}
+			add(s, "Kubernetes Dashboard with --enable-skip-login: anyone reaching it acts as the Dashboard service account")
+		}
+		if flags["enable-insecure-login"] == "true" {
+			add(model.SeverityMedium, "Kubernetes Dashboard with --enable-insecure-login: tokens are accepted over plain HTTP")
+		}
+
+	case repositoryMatches(ref.Repository, tillerImages...):
+		tls := flags["tls-verify"] == "true"
+		for _, e := range c.Env {
+			if e.Name == "TILLER_TLS_VERIFY" && e.Value == "1" {
+				tls = true
+			}
+		}
+		listen := flags["listen"]
+		local := strings.HasPrefix(listen, "localhost:") || strings.HasPrefix(listen, "127.0.0.1:")
+		if !tls && !local {
+			add(model.SeverityCritical, fmt.Sprintf("Tiller (Helm v2) gRPC on %s without TLS client verification: any pod can install charts with Tiller's privileges", valueOr(listen, ":44134")))
+		} else {
+			add(model.SeverityMedium, "Tiller (Helm v2) is end-of-life since 2020-11-13 and receives no security fixes")
+		}
+
+	case repositoryMatches(ref.Repository, metricsServerImages...):
+		if flags["deprecated-kubelet-completely-insecure"] == "true" {
+			add(model.SeverityHigh, "metrics-server with --deprecated-kubelet-completely-insecure: kubelets are scraped over plain HTTP without authentication")
+		}
+		if flags["kubelet-insecure-tls"] == "true" {
+			add(model.SeverityLow, "metrics-server with --kubelet-insecure-tls: kubelet serving certificates are not verified")
+		}
+
+	case repositoryMatches(ref.Repository, ingressNginxImages...):
+		if risk := ingressNginxSnippets(p, flags, ref, cms); risk != "" {
+			add(model.SeverityHigh, risk)
+		}
+	}
+	return risks, sev
+}
+
+// ingressNginxSnippets reports whether snippet annotations are accepted. They are allowed by
+// default before 1.9.0; from 1.12.0 they also need annotations-risk-level: Critical.
+func ingressNginxSnippets(p k8s.Pod, flags map[string]string, ref imageref.Ref, cms map[string]k8s.ConfigMap) string {
+	v, versioned := parseVersion(ref.Tag)
+	cmName := flags["configmap"]
+	if cmName != "" && !strings.Contains(cmName, "/") {
+		cmName = p.Metadata.Namespace + "/" + cmName
+	}
+	cm, found := cms[cmName]
+	allow, set := cm.Data["allow-snippet-annotations"]
+	switch {
+	case set:
+		if allow != "true" {
+			return ""
+		}
+	case (found || cmName == "") && versioned && versionLess(v, [3]int{1, 9, 0}):
+		allow = "true (default before 1.9.0)"
+	default:
+		return "" // default off, or the ConfigMap is not visible
+	}
+	if versioned && !versionLess(v, [3]int{1, 12, 0}) && cm.Data["annotations-risk-level"] != "Critical" {
+		return ""
+	}
+	return fmt.Sprintf("ingress-nginx ConfigMap %s: allow-snippet-annotations=%s: users who can create Ingress inject nginx configuration and read the controller token and Secrets", valueOr(cmName, "(none)"), allow)
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
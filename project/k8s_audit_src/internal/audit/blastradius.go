@@ -0,0 +1,294 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Secret blast radius: RBAC readers + consuming pods + who can exec into them.
+
+// wideReaders is the number of non-system principals after which a Secret counts as widely readable.
+const wideReaders = 5
+
+// broadGroupWeight is the score of a group that contains every (service) account.
+const broadGroupWeight = 50
+
+var highValueSecretTypes = []string{
+	"kubernetes.io/service-account-token", "kubernetes.io/tls", "kubernetes.io/dockerconfigjson",
+	"kubernetes.io/dockercfg", "kubernetes.io/basic-auth", "kubernetes.io/ssh-auth",
+}
+
+var highValueSecretName = regexp.MustCompile(`(?i)(admin|root|master|prod|database|\bdb\b|postgres|mysql|mongo|redis|aws|gcp|azure|vault|token|api[-_]?key|cred|passw|private|signing)`)
+
+// secrets that are consumed by controllers rather than pods
+var unusedExemptTypes = []string{"kubernetes.io/service-account-token", "helm.sh/release.v1", "bootstrap.kubernetes.io/token"}
+
+type SecretGraphInputs struct {
+	Namespaces []k8s.Namespace
+	// Secrets is nil unless -analyze-secrets; then the graph is built from references only.
+	Secrets []k8s.Secret
+	Pods    []k8s.Pod
+	// PodsListed is false when pods could not be listed; unused Secrets are then not reported.
+	PodsListed      bool
+	ServiceAccounts []k8s.ServiceAccount
+	Ingresses       []k8s.Ingress
+	Gateways        []k8s.Gateway
+	RBAC            EffectiveRBAC
+}
+
+// DetectSecretBlastRadius computes per-Secret exposure and reports widely readable
+// high-value Secrets and (when Secrets and pods were listed) unused ones.
+func DetectSecretBlastRadius(in SecretGraphInputs) ([]model.Finding, []model.SecretExposure) {
+	allowed := map[string]struct{}{}
+	for _, ns := range in.Namespaces {
+		allowed[ns.Metadata.Name] = struct{}{}
+	}
+
+	consumers, consumerPods := secretConsumers(in)
+
+	types := map[string]string{}
+	listed := in.Secrets != nil
+	if listed {
+		for _, s := range in.Secrets {
+			types[s.Metadata.Namespace+"/"+s.Metadata.Name] = s.Type
+		}
+	} else {
+		for key := range consumers {
+			types[key] = ""
+		}
+	}
+	podsBySA := map[string][]string{}
+	for _, p := range in.Pods {
+		sa := p.Metadata.Namespace + "/" + podServiceAccount(p)
+		podsBySA[sa] = append(podsBySA[sa], p.Metadata.Namespace+"/"+p.Metadata.Name)
+	}
+
+	var out []model.Finding
+	var exposure []model.SecretExposure
+	for key, typ := range types {
+		ns, name, _ := strings.Cut(key, "/")
+		if _, ok := allowed[ns]; !ok {
+			continue
+		}
+		res := model.ResourceRef{Kind: "Secret", Namespace: ns, Name: name}
+		readers := secretSubjects(in.RBAC, func(b []BoundRole) bool {
+			return allows(b, ns, "secrets", name, "get", "list", "watch")
+		})
+
+		// anyone able to exec into a consuming pod can read the mounted/env value
+		var execVia []string
+		for _, pk := range uniqStrings(consumerPods[key]) {
+			_, pod, _ := strings.Cut(pk, "/")
+			subs := secretSubjects(in.RBAC, func(b []BoundRole) bool {
+				return allows(b, ns, "pods/exec", pod, "create", "get")
+			})
+			for _, s := range subs {
+				execVia = append(execVia, s+" -> Pod/"+pk)
+				if saKey, ok := strings.CutPrefix(s, "ServiceAccount/"); ok {
+					for _, src := range podsBySA[saKey] {
+						execVia = append(execVia, "Pod/"+src+" -> Pod/"+pk)
+					}
+				}
+			}
+		}
+		execVia = uniqStrings(execVia)
+
+		principals := map[string]struct{}{}
+		broad := []string{}
+		wide := 0
+		for _, r := range readers {
+			principals[r] = struct{}{}
+		}
+		for _, e := range execVia {
+			src, _, _ := strings.Cut(e, " -> ")
+			principals[src] = struct{}{}
+		}
+		score := 0
+		for p := range principals {
+			switch {
+			case isBroadGroup(p):
+				broad = append(broad, p)
+				score += broadGroupWeight
+			case !isSystemPrincipal(p):
+				wide++
+				score++
+			default:
+				score++
+			}
+		}
+		sort.Strings(broad)
+
+		exposure = append(exposure, model.SecretExposure{
+			Namespace: ns,
+			Name:      name,
+			Type:      typ,
+			Score:     score,
+			Readers:   readers,
+			Consumers: uniqStrings(consumers[key]),
+			ExecVia:   execVia,
+		})
+
+		if highValueSecret(name, typ) && (len(broad) > 0 || wide >= wideReaders) {
+			sev := model.SeverityHigh
+			if len(broad) > 0 {
+				sev = model.SeverityCritical
+			}
+			ev := fmt.Sprintf("score=%d; %d non-system principals can read it (%d via RBAC, %d via exec into consumers)", score, wide, len(readers), len(execVia))
+			if len(broad) > 0 {
+				ev += "; broad groups: " + strings.Join(broad, ", ")
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SEC-006",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Ценный Secret доступен широкому кругу субъектов",
+				Evidence:       truncateEvidence(ev+"; readers: "+strings.Join(readers, ", "), 1024),
+				Risk:           "Компрометация любого из субъектов или Pod'ов с exec раскрывает секрет",
+				Recommendation: "Сузить get/list/watch на secrets (resourceNames, отдельный namespace), убрать pods/exec у лишних субъектов",
+			})
+		}
+
+		if listed && in.PodsListed && len(consumers[key]) == 0 && !containsAny(unusedExemptTypes, typ) && !isSystemNamespace(ns) {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SEC-007",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "Неиспользуемый Secret",
+				Evidence:       fmt.Sprintf("type=%s not referenced by pods, ServiceAccounts, Ingresses or Gateways; readable by %d subjects", typ, len(readers)),
+				Risk:           "Забытые секреты часто содержат действующие учетные данные и не ротируются",
+				Recommendation: "Удалить Secret или отозвать содержащиеся в нем учетные данные",
+			})
+		}
+	}
+
+	sort.Slice(exposure, func(i, j int) bool {
+		if exposure[i].Score != exposure[j].Score {
+			return exposure[i].Score > exposure[j].Score
+		}
+		return exposure[i].Namespace+"/"+exposure[i].Name < exposure[j].Namespace+"/"+exposure[j].Name
+	})
+	return out, exposure
+}
+
+// secretConsumers maps namespace/secret to referencing objects, and separately to consuming pods.
+func secretConsumers(in SecretGraphInputs) (map[string][]string, map[string][]string) {
+	refs := map[string][]string{}
+	pods := map[string][]string{}
+	for _, p := range in.Pods {
+		ns := p.Metadata.Namespace
+		pk := ns + "/" + p.Metadata.Name
+		var names []string
+		for _, v := range p.Spec.Volumes {
+			if v.Secret != nil {
+				names = append(names, v.Secret.SecretName)
+			}
+			if v.Projected != nil {
+				for _, src := range v.Projected.Sources {
+					if src.Secret != nil {
+						names = append(names, src.Secret.Name)
+					}
+				}
+			}
+		}
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			for _, ev := range c.Env {
+				if ev.ValueFrom != nil && ev.ValueFrom.SecretKeyRef != nil {
+					names = append(names, ev.ValueFrom.SecretKeyRef.Name)
+				}
+			}
+			for _, ef := range c.EnvFrom {
+				if ef.SecretRef != nil {
+					names = append(names, ef.SecretRef.Name)
+				}
+			}
+		}
+		for _, ips := range p.Spec.ImagePullSecrets {
+			names = append(names, ips.Name)
+		}
+		for _, n := range uniqStrings(names) {
+			refs[ns+"/"+n] = append(refs[ns+"/"+n], "Pod/"+pk)
+			pods[ns+"/"+n] = append(pods[ns+"/"+n], pk)
+		}
+	}
+	for _, sa := range in.ServiceAccounts {
+		ns := sa.Metadata.Namespace
+		for _, r := range append(append([]k8s.LocalObjectReference{}, sa.Secrets...), sa.ImagePullSecrets...) {
+			refs[ns+"/"+r.Name] = append(refs[ns+"/"+r.Name], "ServiceAccount/"+ns+"/"+sa.Metadata.Name)
+		}
+	}
+	for _, ig := range in.Ingresses {
+		ns := ig.Metadata.Namespace
+		for _, t := range ig.Spec.TLS {
+			if t.SecretName != "" {
+				refs[ns+"/"+t.SecretName] = append(refs[ns+"/"+t.SecretName], "Ingress/"+ns+"/"+ig.Metadata.Name)
+			}
+		}
+	}
+	for _, gw := range in.Gateways {
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil {
+				continue
+			}
+			for _, c := range l.TLS.CertificateRefs {
+				if c.Kind != "" && c.Kind != "Secret" {
+					continue
+				}
+				ns := c.Namespace
+				if ns == "" {
+					ns = gw.Metadata.Namespace
+				}
+				refs[ns+"/"+c.Name] = append(refs[ns+"/"+c.Name], "Gateway/"+gw.Metadata.Namespace+"/"+gw.Metadata.Name)
+			}
+		}
+	}
+	return refs, pods
+}
+
+// secretSubjects returns ServiceAccounts, Users and Groups whose bindings satisfy ok.
+func secretSubjects(e EffectiveRBAC, ok func([]BoundRole) bool) []string {
+	var out []string
+	for sa, bound := range e.BySA {
+		if ok(bound) {
+			out = append(out, "ServiceAccount/"+sa)
+		}
+	}
+	for sub, bound := range e.BySubject {
+		if ok(bound) {
+			out = append(out, sub)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func highValueSecret(name, typ string) bool {
+	return containsAny(highValueSecretTypes, typ) || highValueSecretName.MatchString(name)
+}
+
+func isBroadGroup(p string) bool {
+	return p == "Group/system:authenticated" || p == "Group/system:unauthenticated" ||
+		p == "Group/system:serviceaccounts" || strings.HasPrefix(p, "Group/system:serviceaccounts:")
+}
+
+// isSystemPrincipal covers control-plane identities that legitimately read Secrets.
+func isSystemPrincipal(p string) bool {
+	if strings.HasPrefix(p, "User/system:") || p == "Group/system:masters" || strings.HasPrefix(p, "Group/system:nodes") {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(p, "ServiceAccount/"); ok {
+		ns, _, _ := strings.Cut(rest, "/")
+		return isSystemNamespace(ns)
+	}
+	if rest, ok := strings.CutPrefix(p, "Pod/"); ok {
+		ns, _, _ := strings.Cut(rest, "/")
+		return isSystemNamespace(ns)
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+type capability struct {
+	Severity model.Severity // "" for capabilities that grant nothing worth reporting
+	Effect   string
+	Escape   string // known abuse technique, if any
+}
+
+// capabilityCatalogue rates Linux capabilities by what they give an attacker inside a container.
+var capabilityCatalogue = map[string]capability{
+	// escape-grade
+	"SYS_ADMIN":          {model.SeverityCritical, "mount, namespaces, cgroups and most admin syscalls", "cgroup v1 release_agent escape (CVE-2022-0492), mounting host block devices"},
+	"SYS_MODULE":         {model.SeverityCritical, "load and unload kernel modules", "insmod of a rootkit module into the host kernel"},
+	"SYS_PTRACE":         {model.SeverityCritical, "ptrace and process_vm_* on any process in the PID namespace", "code injection into host processes with hostPID"},
+	"DAC_READ_SEARCH":    {model.SeverityCritical, "bypass read permission checks, open_by_handle_at", "\"shocker\" escape: open host files by inode handle"},
+	"BPF":                {model.SeverityCritical, "load eBPF programs and maps", "kernel memory read/write via eBPF, kprobe-based credential theft"},
+	"NET_ADMIN":          {model.SeverityCritical, "configure interfaces, routes, iptables, tc", "hijack or sniff node traffic with hostNetwork, bypass NetworkPolicy"},
+	"SYS_RAWIO":          {model.SeverityCritical, "raw I/O ports and /dev/mem", "direct host memory and disk access"},
+	"SYS_BOOT":           {model.SeverityHigh, "reboot and kexec_load", "boot a different kernel on the node"},
+	"MAC_ADMIN":          {model.SeverityHigh, "configure AppArmor/SELinux/Smack", "unload the MAC profile confining the container"},
+	"MAC_OVERRIDE":       {model.SeverityHigh, "override mandatory access control", "bypass AppArmor/SELinux confinement"},
+	"CHECKPOINT_RESTORE": {model.SeverityHigh, "checkpoint/restore processes, set arbitrary PIDs", "read memory of checkpointed processes"},
+	"SYSLOG":             {model.SeverityHigh, "read kernel log and kernel pointers", "KASLR bypass for kernel exploits"},
+	"PERFMON":            {model.SeverityHigh, "perf_event_open and BPF tracing", "side channels and kernel address leaks"},
+	// default runtime set
+	"NET_RAW":          {model.SeverityMedium, "raw and packet sockets", "ARP/DNS spoofing of pods on the same node"},
+	"DAC_OVERRIDE":     {model.SeverityMedium, "bypass file read/write/execute permission checks", ""},
+	"SETUID":           {model.SeverityMedium, "arbitrary setuid", ""},
+	"SETGID":           {model.SeverityMedium, "arbitrary setgid and supplementary groups", ""},
+	"SETPCAP":          {model.SeverityMedium, "modify capability bounding sets of own processes", ""},
+	"SETFCAP":          {model.SeverityMedium, "set file capabilities", "privilege persistence via file capabilities"},
+	"MKNOD":            {model.SeverityMedium, "create device nodes", "access to host devices where device cgroup allows it"},
+	"CHOWN":            {model.SeverityLow, "change file ownership", ""},
+	"FOWNER":           {model.SeverityLow, "bypass owner checks on files", ""},
+	"FSETID":           {model.SeverityLow, "keep setuid bits on modified files", ""},
+	"KILL":             {model.SeverityLow, "signal processes of other users", ""},
+	"SYS_CHROOT":       {model.SeverityLow, "chroot", ""},
+	"AUDIT_WRITE":      {model.SeverityLow, "write to the kernel audit log", ""},
+	"NET_BIND_SERVICE": {"", "bind ports below 1024", ""},
+	// other
+	"SYS_TIME":        {model.SeverityMedium, "set the system clock", "break TLS and token expiry on the whole node"},
+	"SYS_RESOURCE":    {model.SeverityMedium, "override resource limits", "node resource exhaustion"},
+	"AUDIT_CONTROL":   {model.SeverityMedium, "configure kernel auditing", "disable host audit trail"},
+	"LINUX_IMMUTABLE": {model.SeverityMedium, "set immutable and append-only file attributes", ""},
+	"IPC_OWNER":       {model.SeverityMedium, "bypass IPC permission checks", ""},
+	"SYS_NICE":        {model.SeverityLow, "raise scheduling priority", ""},
+	"IPC_LOCK":        {model.SeverityLow, "lock memory", ""},
+	"NET_BROADCAST":   {model.SeverityLow, "broadcast and multicast", ""},
+	"AUDIT_READ":      {model.SeverityLow, "read the audit log via netlink", ""},
+	"SYS_PACCT":       {model.SeverityLow, "process accounting", ""},
+	"SYS_TTY_CONFIG":  {model.SeverityLow, "configure TTY devices", ""},
+	"LEASE":           {model.SeverityLow, "file leases", ""},
+	"WAKE_ALARM":      {model.SeverityLow, "set wake alarms", ""},
+	"BLOCK_SUSPEND":   {model.SeverityLow, "block system suspend", ""},
+}
+
+// runtimeDefaultCaps is the containerd/CRI-O/Docker default capability set.
+var runtimeDefaultCaps = []string{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "NET_RAW", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+func normalizeCap(s string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "CAP_")
+}
+
+func lookupCapability(c string) capability {
+	if info, ok := capabilityCatalogue[c]; ok {
+		return info
+	}
+	return capability{Severity: model.SeverityMedium, Effect: "unknown capability"}
+}
+
+// effectiveCaps applies drop then add to the runtime default set. all is true for add: [ALL].
+func effectiveCaps(caps *k8s.Capabilities) (effective []string, all bool) {
+	set := map[string]struct{}{}
+	for _, c := range runtimeDefaultCaps {
+		set[c] = struct{}{}
+	}
+	if caps != nil {
+		for _, c := range caps.Drop {
+			c = normalizeCap(c)
+			if c == "ALL" {
+				set = map[string]struct{}{}
+				break
+			}
+			delete(set, c)
+		}
+		for _, c := range caps.Add {
+			c = normalizeCap(c)
+			if c == "ALL" {
+				all = true
+				continue
+			}
+			set[c] = struct{}{}
+		}
+	}
+	for c := range set {
+		effective = append(effective, c)
+	}
+	sort.Strings(effective)
+	return effective, all
+}
+
+func detectCapabilities(p k8s.Pod, ctn k8s.Container) []model.Finding {
+	ctx := ctn.SecurityContext
+	if ctx != nil && ctx.Privileged != nil && *ctx.Privileged {
+		return nil // K8S-POD-001
+	}
+	var caps *k8s.Capabilities
+	if ctx != nil {
+		caps = ctx.Capabilities
+	}
+	res := model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name}
+	effective, all := effectiveCaps(caps)
+	var out []model.Finding
+
+	if caps != nil && len(caps.Add) > 0 {
+		sev := model.Severity("")
+		var parts []string
+		for _, c := range uniqStrings(caps.Add) {
+			c = normalizeCap(c)
+			if c == "ALL" {
+				sev = model.SeverityCritical
+				parts = append(parts, "ALL: every capability, close to privileged")
+				continue
+			}
+			info := lookupCapability(c)
+			if info.Severity == "" {
+				continue
+			}
+			if model.SeverityRank(info.Severity) > model.SeverityRank(sev) {
+				sev = info.Severity
+			}
+			s := fmt.Sprintf("%s [%s]: %s", c, info.Severity, info.Effect)
+			if info.Escape != "" {
+				s += "; abuse: " + info.Escape
+			}
+			parts = append(parts, s)
+		}
+		if sev != "" {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-POD-010",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Добавлены опасные Linux capabilities",
+				Evidence:       fmt.Sprintf("container %q: capabilities.add: %s", ctn.Name, strings.Join(parts, "; ")),
+				Risk:           "Capabilities из списка позволяют выйти из контейнера или атаковать узел и соседние Pod'ы",
+				Recommendation: "Убрать capabilities.add или оставить минимально необходимые (например, NET_BIND_SERVICE)",
+			})
+		}
+	}
+
+	droppedAll := false
+	if caps != nil {
+		for _, c := range caps.Drop {
+			if normalizeCap(c) == "ALL" {
+				droppedAll = true
+			}
+		}
+	}
+	if !droppedAll && !all {
+		var extra, kept []string
+		for _, c := range effective {
+			if containsAny(runtimeDefaultCaps, c) {
+				if lookupCapability(c).Severity == model.SeverityMedium {
+					kept = append(kept, c)
+				}
+			} else {
+				extra = append(extra, c)
+			}
+		}
+		ev := fmt.Sprintf("container %q: effective capabilities %v", ctn.Name, effective)
+		if caps == nil || len(caps.Drop) == 0 {
+			ev += " (runtime default, capabilities.drop not set)"
+		} else {
+			ev += fmt.Sprintf(" (runtime default minus %v", caps.Drop)
+			if len(extra) > 0 {
+				ev += fmt.Sprintf(" plus %v", extra)
+			}
+			ev += ")"
+		}
+		if len(kept) > 0 {
+			ev += fmt.Sprintf("; risky defaults kept: %v", kept)
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-011",
+			Severity:       model.SeverityLow,
+			Resource:       res,
+			Title:          "Не сброшены capabilities по умолчанию",
+			Evidence:       ev,
+			Risk:           "По умолчанию контейнер сохраняет набор стандартных capabilities (NET_RAW, DAC_OVERRIDE, SETUID и др.)",
+			Recommendation: "Явно сбросить все capabilities (drop: [\"ALL\"]) и добавить нужные",
+		})
+	}
+	return out
+}
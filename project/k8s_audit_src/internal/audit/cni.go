@@ -0,0 +1,266 @@
+package audit
+
+import (
+	"regexp"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+)
+
+// CNIPolicies holds Cilium and Calico policy objects (empty if the CRDs are absent).
+type CNIPolicies struct {
+	Cilium            []k8s.CiliumNetworkPolicy
+	CiliumClusterwide []k8s.CiliumNetworkPolicy
+	Calico            []k8s.CalicoNetworkPolicy
+	CalicoGlobal      []k8s.CalicoNetworkPolicy
+}
+
+// cniVerdict is what CNI-specific policies contribute to a namespace's segmentation.
+type cniVerdict struct {
+	Policies    []string // policies that apply to the namespace
+	DenyIngress []string // policies putting every pod into ingress default-deny
+	DenyEgress  []string
+}
+
+// Pseudo-labels Cilium and Calico attach to every endpoint of a namespace.
+var (
+	ciliumNamespaceKeys = []string{"io.kubernetes.pod.namespace", "k8s:io.kubernetes.pod.namespace"}
+	ciliumNSLabelPrefix = []string{"io.cilium.k8s.namespace.labels.", "k8s:io.cilium.k8s.namespace.labels."}
+	calicoNamespaceKeys = []string{"projectcalico.org/namespace"}
+)
+
+func cniSegmentation(ns k8s.Namespace, c CNIPolicies) cniVerdict {
+	var v cniVerdict
+	name := ns.Metadata.Name
+
+	ciliumRules := func(p k8s.CiliumNetworkPolicy) []k8s.CiliumRule {
+		rules := append([]k8s.CiliumRule{}, p.Specs...)
+		if p.Spec != nil {
+			rules = append(rules, *p.Spec)
+		}
+		return rules
+	}
+	addCilium := func(kind string, p k8s.CiliumNetworkPolicy, clusterwide bool) {
+		label := kind + "/" + p.Metadata.Name
+		applies := false
+		for _, r := range ciliumRules(p) {
+			if r.EndpointSelector == nil {
+				continue // host policy (nodeSelector)
+			}
+			if clusterwide && !ciliumSelectsNamespace(*r.EndpointSelector, ns) {
+				continue
+			}
+			applies = true
+			if !clusterwide && !selectorEmpty(*r.EndpointSelector) {
+				continue
+			}
+			defIn, defEg := true, true
+			if d := r.EnableDefaultDeny; d != nil {
+				defIn = d.Ingress == nil || *d.Ingress
+				defEg = d.Egress == nil || *d.Egress
+			}
+			if defIn && (r.Ingress != nil || r.IngressDeny != nil) && !ciliumAllowsAll(r.Ingress, true) {
+				v.DenyIngress = append(v.DenyIngress, label)
+			}
+			if defEg && (r.Egress != nil || r.EgressDeny != nil) && !ciliumAllowsAll(r.Egress, false) {
+				v.DenyEgress = append(v.DenyEgress, label)
+			}
+		}
+		if applies {
+			v.Policies = append(v.Policies, label)
+		}
+	}
+	for _, p := range c.Cilium {
+		if p.Metadata.Namespace == name {
+			addCilium("CiliumNetworkPolicy", p, false)
+		}
+	}
+	for _, p := range c.CiliumClusterwide {
+		addCilium("CiliumClusterwideNetworkPolicy", p, true)
+	}
+
+	addCalico := func(kind string, p k8s.CalicoNetworkPolicy, global bool) {
+		label := kind + "/" + p.Metadata.Name
+		if global {
+			if p.Spec.NamespaceSelector != "" {
+				nsLabels := map[string]string{"projectcalico.org/name": name}
+				for k, val := range ns.Metadata.Labels {
+					nsLabels[k] = val
+				}
+				match, _, ok := calicoSelector(p.Spec.NamespaceSelector, nsLabels)
+				if !ok || !match {
+					return
+				}
+			}
+		}
+		match, keys, ok := calicoSelector(p.Spec.Selector, map[string]string{"projectcalico.org/namespace": name})
+		if !ok {
+			if !global {
+				v.Policies = append(v.Policies, label)
+			}
+			return
+		}
+		allPods := match && onlyKeys(keys, calicoNamespaceKeys)
+		if global && !allPods {
+			return
+		}
+		v.Policies = append(v.Policies, label)
+		if !allPods {
+			return
+		}
+		types := uniqStrings(p.Spec.Types)
+		if len(types) == 0 {
+			types = []string{"Ingress"}
+			if len(p.Spec.Egress) > 0 {
+				types = append(types, "Egress")
+			}
+		}
+		if containsAny(types, "Ingress") && !calicoAllowsAll(p.Spec.Ingress) {
+			v.DenyIngress = append(v.DenyIngress, label)
+		}
+		if containsAny(types, "Egress") && !calicoAllowsAll(p.Spec.Egress) {
+			v.DenyEgress = append(v.DenyEgress, label)
+		}
+	}
+	for _, p := range c.Calico {
+		if p.Metadata.Namespace == name {
+			addCalico("CalicoNetworkPolicy", p, false)
+		}
+	}
+	for _, p := range c.CalicoGlobal {
+		addCalico("GlobalNetworkPolicy", p, true)
+	}
+
+	v.Policies = uniqStrings(v.Policies)
+	v.DenyIngress = uniqStrings(v.DenyIngress)
+	v.DenyEgress = uniqStrings(v.DenyEgress)
+	return v
+}
+
+// ciliumSelectsNamespace reports whether a clusterwide endpointSelector covers every pod of ns.
+func ciliumSelectsNamespace(sel k8s.LabelSelector, ns k8s.Namespace) bool {
+	pseudo := map[string]string{}
+	for _, k := range ciliumNamespaceKeys {
+		pseudo[k] = ns.Metadata.Name
+	}
+	for k, val := range ns.Metadata.Labels {
+		for _, prefix := range ciliumNSLabelPrefix {
+			pseudo[prefix+k] = val
+		}
+	}
+	keys := []string{}
+	for k := range sel.MatchLabels {
+		keys = append(keys, k)
+	}
+	for _, r := range sel.MatchExpressions {
+		keys = append(keys, r.Key)
+	}
+	for _, k := range keys {
+		if _, ok := pseudo[k]; !ok && !hasAnyPrefix(k, ciliumNSLabelPrefix) {
+			return false
+		}
+	}
+	return selectorMatches(sel, pseudo)
+}
+
+func ciliumAllowsAll(rules []k8s.CiliumPolicyRule, ingress bool) bool {
+	for _, r := range rules {
+		entities := r.ToEntities
+		if ingress {
+			entities = r.FromEntities
+		}
+		if containsAny(entities, "all") {
+			return true
+		}
+	}
+	return false
+}
+
+func calicoAllowsAll(rules []k8s.CalicoRule) bool {
+	for _, r := range rules {
+		if !strings.EqualFold(r.Action, "Allow") || r.Protocol != nil {
+			continue
+		}
+		if calicoEntityEmpty(r.Source) && calicoEntityEmpty(r.Destination) {
+			return true
+		}
+	}
+	return false
+}
+
+func calicoEntityEmpty(e k8s.CalicoEntityRule) bool {
+	return len(e.Nets) == 0 && len(e.NotNets) == 0 && e.Selector == "" && e.NamespaceSelector == "" &&
+		len(e.Ports) == 0 && len(e.Domains) == 0
+}
+
+var (
+	calicoHas   = regexp.MustCompile(`^(!?)has\(\s*([^)\s]+)\s*\)$`)
+	calicoEq    = regexp.MustCompile(`^([^\s!=]+)\s*(==|!=)\s*['"]([^'"]*)['"]$`)
+	calicoIn    = regexp.MustCompile(`^([^\s]+)\s+(in|not in)\s*\{([^}]*)\}$`)
+	calicoQuote = regexp.MustCompile(`['"]([^'"]*)['"]`)
+)
+
+// calicoSelector evaluates the conjunctive subset of the Calico selector language.
+// ok is false for expressions it cannot evaluate (||, parentheses, string operators).
+func calicoSelector(expr string, labels map[string]string) (match bool, keys []string, ok bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "all()" {
+		return true, nil, true
+	}
+	if strings.Contains(expr, "||") {
+		return false, nil, false
+	}
+	match = true
+	for _, term := range strings.Split(expr, "&&") {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == "all()":
+		case calicoHas.MatchString(term):
+			m := calicoHas.FindStringSubmatch(term)
+			_, present := labels[m[2]]
+			keys = append(keys, m[2])
+			if present == (m[1] == "!") {
+				match = false
+			}
+		case calicoEq.MatchString(term):
+			m := calicoEq.FindStringSubmatch(term)
+			val, present := labels[m[1]]
+			keys = append(keys, m[1])
+			if (m[2] == "==") != (present && val == m[3]) {
+				match = false
+			}
+		case calicoIn.MatchString(term):
+			m := calicoIn.FindStringSubmatch(term)
+			vals := []string{}
+			for _, q := range calicoQuote.FindAllStringSubmatch(m[3], -1) {
+				vals = append(vals, q[1])
+			}
+			val, present := labels[m[1]]
+			keys = append(keys, m[1])
+			if (m[2] == "in") != (present && containsAny(vals, val)) {
+				match = false
+			}
+		default:
+			return false, nil, false
+		}
+	}
+	return match, keys, true
+}
+
+func onlyKeys(keys, allowed []string) bool {
+	for _, k := range keys {
+		if !containsAny(allowed, k) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
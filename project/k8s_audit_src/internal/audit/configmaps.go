@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/secretscan"
+)
+
+// DetectConfigMapSecrets scans ConfigMap data and binaryData for credentials. Pod usage is
+// only stated when podsListed is true.
+func DetectConfigMapSecrets(namespaces []k8s.Namespace, cms []k8s.ConfigMap, pods []k8s.Pod, podsListed bool, e EffectiveRBAC) []model.Finding {
+	allowed := map[string]struct{}{}
+	for _, ns := range namespaces {
+		allowed[ns.Metadata.Name] = struct{}{}
+	}
+	users := configMapUsers(pods)
+
+	var out []model.Finding
+	for _, cm := range cms {
+		ns := cm.Metadata.Namespace
+		if _, ok := allowed[ns]; !ok {
+			continue
+		}
+		keys := make([]string, 0, len(cm.Data)+len(cm.BinaryData))
+		values := map[string]string{}
+		for k, v := range cm.Data {
+			keys = append(keys, k)
+			values[k] = v
+		}
+		for k, b := range cm.BinaryData {
+			if !utf8.Valid(b) {
+				continue
+			}
+			keys = append(keys, k)
+			values[k] = string(b)
+		}
+		sort.Strings(keys)
+
+		var context string
+		contextDone := false
+		for _, k := range keys {
+			v := values[k]
+			var matches []secretscan.Match
+			if strings.Contains(strings.TrimSpace(v), "\n") {
+				matches = secretscan.ScanText(v)
+			} else {
+				matches = secretscan.ScanValue(k, v)
+				for i := range matches {
+					matches[i].Key = ""
+				}
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			if !contextDone {
+				context, contextDone = configMapContext(cm, users, podsListed, e), true
+			}
+			parts := make([]string, 0, len(matches))
+			for _, m := range matches {
+				parts = append(parts, m.String())
+			}
+			ev := fmt.Sprintf("key %q: %s", k, strings.Join(parts, "; "))
+			if context != "" {
+				ev += "; " + context
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-CM-001",
+				Severity:       credentialSeverity(matches),
+				Resource:       model.ResourceRef{Kind: "ConfigMap", Namespace: ns, Name: cm.Metadata.Name},
+				Title:          "Учетные данные в ConfigMap",
+				Evidence:       truncateEvidence(ev, 1024),
+				Risk:           "ConfigMap не шифруется в etcd и читается гораздо более широким кругом ролей, чем Secret",
+				Recommendation: "Перенести значение в Secret, ротировать раскрытые учетные данные и ограничить чтение ConfigMap",
+			})
+		}
+	}
+	return out
+}
+
+// configMapUsers maps namespace/configmap to pods that mount or env-import it.
+func configMapUsers(pods []k8s.Pod) map[string][]string {
+	out := map[string][]string{}
+	for _, p := range pods {
+		ns := p.Metadata.Namespace
+		pk := ns + "/" + p.Metadata.Name
+		add := func(name string) {
+			out[ns+"/"+name] = append(out[ns+"/"+name], pk)
+		}
+		for _, v := range p.Spec.Volumes {
+			if v.ConfigMap != nil {
+				add(v.ConfigMap.Name)
+			}
+			if v.Projected != nil {
+				for _, src := range v.Projected.Sources {
+					if src.ConfigMap != nil {
+						add(src.ConfigMap.Name)
+					}
+				}
+			}
+		}
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			for _, ev := range c.Env {
+				if ev.ValueFrom != nil && ev.ValueFrom.ConfigMapKeyRef != nil {
+					add(ev.ValueFrom.ConfigMapKeyRef.Name)
+				}
+			}
+			for _, ef := range c.EnvFrom {
+				if ef.ConfigMapRef != nil {
+					add(ef.ConfigMapRef.Name)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// configMapContext describes who consumes the ConfigMap and who can read it without Secret access.
+func configMapContext(cm k8s.ConfigMap, users map[string][]string, podsListed bool, e EffectiveRBAC) string {
+	ns := cm.Metadata.Namespace
+	var parts []string
+	if ps := uniqStrings(users[ns+"/"+cm.Metadata.Name]); len(ps) > 0 {
+		parts = append(parts, "used by pods: "+strings.Join(ps, ","))
+	} else if podsListed {
+		parts = append(parts, "not used by any pod")
+	}
+	readers, noSecrets := 0, 0
+	for _, bound := range e.BySA {
+		if !canRead(bound, ns, "configmaps") {
+			continue
+		}
+		readers++
+		if !canRead(bound, ns, "secrets") {
+			noSecrets++
+		}
+	}
+	if readers > 0 {
+		parts = append(parts, fmt.Sprintf("readable by %d service accounts (%d of them cannot read Secrets)", readers, noSecrets))
+	}
+	return strings.Join(parts, "; ")
+}
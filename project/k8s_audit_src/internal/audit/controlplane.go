@@ -0,0 +1,354 @@
+package audit
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Control-plane components of self-managed clusters run as kube-system (static) pods.
+var controlPlaneComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "etcd"}
+
+// CIS minimums for audit log retention.
+var auditLogMinimums = []struct {
+	flag string
+	min  int
+}{
+	{"audit-log-maxage", 30},
+	{"audit-log-maxbackup", 10},
+	{"audit-log-maxsize", 100},
+}
+
+// DetectControlPlaneFlags checks kube-apiserver, controller-manager, scheduler and etcd
+// command-line flags against CIS recommendations. pods must include kube-system.
+func DetectControlPlaneFlags(pods []k8s.Pod) []model.Finding {
+	var out []model.Finding
+	for _, p := range pods {
+		if p.Metadata.Namespace != "kube-system" {
+			continue
+		}
+		for _, c := range p.Spec.Containers {
+			comp := controlPlaneComponent(p, c)
+			if comp == "" {
+				continue
+			}
+			flags := parseFlags(append(append([]string{}, c.Command...), c.Args...))
+			res := model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name}
+			switch comp {
+			case "kube-apiserver":
+				out = append(out, detectAPIServerFlags(res, flags)...)
+			case "etcd":
+				out = append(out, detectEtcdFlags(res, etcdSettings(c, flags))...)
+			}
+			if comp != "etcd" && flags["profiling"] != "false" {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-CP-006",
+					Severity:       model.SeverityLow,
+					Resource:       res,
+					Title:          "Профилирование компонента control plane включено",
+					Evidence:       fmt.Sprintf("%s: --profiling=%s", comp, flagValue(flags, "profiling", "true")),
+					Risk:           "Эндпоинт /debug/pprof раскрывает детали работы компонента и может использоваться для DoS",
+					Recommendation: "Установить --profiling=false",
+				})
+			}
+		}
+	}
+	return out
+}
+
+func detectAPIServerFlags(res model.ResourceRef, flags map[string]string) []model.Finding {
+	var out []model.Finding
+
+	if flags["anonymous-auth"] != "false" {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-001",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Анонимные запросы к kube-apiserver разрешены",
+			Evidence:       "kube-apiserver: --anonymous-auth=" + flagValue(flags, "anonymous-auth", "true"),
+			Risk:           "Неаутентифицированные запросы получают права system:anonymous и system:unauthenticated; ошибочная привязка к этим субъектам открывает кластер",
+			Recommendation: "Установить --anonymous-auth=false (или ограничить анонимный доступ health-эндпоинтами через AuthenticationConfiguration)",
+		})
+	}
+
+	modes := splitFlagList(flags["authorization-mode"])
+	structured := len(modes) == 0 && flags["authorization-config"] != ""
+	if len(modes) == 0 {
+		modes = []string{"AlwaysAllow"} // kube-apiserver default
+	}
+	switch {
+	case structured:
+		// --authorization-config replaces the flag; its contents are not visible here
+	case containsAny(modes, "AlwaysAllow"):
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-002",
+			Severity:       model.SeverityCritical,
+			Resource:       res,
+			Title:          "kube-apiserver авторизует все запросы (AlwaysAllow)",
+			Evidence:       "kube-apiserver: --authorization-mode=" + flagValue(flags, "authorization-mode", "AlwaysAllow"),
+			Risk:           "Любой аутентифицированный клиент получает полный доступ к кластеру, RBAC не применяется",
+			Recommendation: "Использовать --authorization-mode=Node,RBAC",
+		})
+	case !containsAny(modes, "RBAC") || !containsAny(modes, "Node"):
+		var missing []string
+		for _, m := range []string{"Node", "RBAC"} {
+			if !containsAny(modes, m) {
+				missing = append(missing, m)
+			}
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-002",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "В --authorization-mode нет Node или RBAC",
+			Evidence:       fmt.Sprintf("kube-apiserver: --authorization-mode=%s; missing %s", flags["authorization-mode"], strings.Join(missing, ", ")),
+			Risk:           "Без Node-авторизатора kubelet может читать объекты чужих узлов; без RBAC права не ограничиваются ролями",
+			Recommendation: "Использовать --authorization-mode=Node,RBAC",
+		})
+	}
+
+	enabled := splitFlagList(flags["enable-admission-plugins"])
+	disabled := splitFlagList(flags["disable-admission-plugins"])
+	var admission []string
+	sev := model.Severity("")
+	if containsAny(enabled, "AlwaysAdmit") {
+		admission = append(admission, "AlwaysAdmit enabled")
+		sev = model.SeverityHigh
+	}
+	if !containsAny(enabled, "NodeRestriction") {
+		admission = append(admission, "NodeRestriction not enabled")
+		if sev == "" {
+			sev = model.SeverityMedium
+		}
+	}
+	for _, d := range []string{"PodSecurity", "ServiceAccount", "NamespaceLifecycle"} {
+		if containsAny(disabled, d) {
+			admission = append(admission, d+" disabled")
+			if sev == "" {
+				sev = model.SeverityMedium
+			}
+		}
+	}
+	if len(admission) > 0 {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-003",
+			Severity:       sev,
+			Resource:       res,
+			Title:          "Небезопасный набор admission-плагинов",
+			Evidence:       "kube-apiserver: " + strings.Join(admission, "; "),
+			Risk:           "Без NodeRestriction скомпрометированный kubelet меняет метки и объекты других узлов; AlwaysAdmit и отключенный PodSecurity пропускают любые Pod'ы",
+			Recommendation: "Включить NodeRestriction, не использовать AlwaysAdmit, не отключать PodSecurity, ServiceAccount и NamespaceLifecycle",
+		})
+	}
+
+	if flags["audit-log-path"] == "" && flags["audit-webhook-config-file"] == "" {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-004",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Аудит kube-apiserver не настроен",
+			Evidence:       "kube-apiserver: --audit-log-path and --audit-webhook-config-file not set",
+			Risk:           "Действия в кластере (включая доступ к Secret и exec) не журналируются, расследование инцидента невозможно",
+			Recommendation: "Задать --audit-policy-file и --audit-log-path (или webhook) с хранением не менее 30 дней",
+		})
+	} else {
+		var weak []string
+		if flags["audit-policy-file"] == "" {
+			weak = append(weak, "--audit-policy-file not set (nothing is logged)")
+		}
+		if flags["audit-log-path"] != "" && flags["audit-log-path"] != "-" {
+			for _, m := range auditLogMinimums {
+				v := flags[m.flag]
+				if n, err := strconv.Atoi(v); err != nil || n < m.min {
+					if v == "" {
+						v = "unset"
+					}
+					weak = append(weak, fmt.Sprintf("--%s=%s (CIS: >= %d)", m.flag, v, m.min))
+				}
+			}
+		}
+		if len(weak) > 0 {
+			sev := model.SeverityLow
+			if flags["audit-policy-file"] == "" {
+				sev = model.SeverityMedium
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-CP-004",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Аудит kube-apiserver настроен не полностью",
+				Evidence:       "kube-apiserver: " + strings.Join(weak, "; "),
+				Risk:           "Без политики аудит не пишет событий, а короткое хранение теряет следы до обнаружения инцидента",
+				Recommendation: "Задать --audit-policy-file и хранение журналов по CIS (maxage 30, maxbackup 10, maxsize 100)",
+			})
+		}
+	}
+
+	if flags["encryption-provider-config"] == "" {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-005",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Шифрование Secret в etcd не настроено",
+			Evidence:       "kube-apiserver: --encryption-provider-config not set",
+			Risk:           "Secret хранятся в etcd открытым текстом: доступ к etcd, его бэкапам или диску узла раскрывает все секреты",
+			Recommendation: "Задать --encryption-provider-config с провайдером aescbc/aesgcm/secretbox или KMS v2",
+		})
+	}
+
+	if flags["kubelet-certificate-authority"] == "" {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-007",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "kube-apiserver не проверяет сертификат kubelet",
+			Evidence:       "kube-apiserver: --kubelet-certificate-authority not set",
+			Risk:           "Соединения apiserver -> kubelet (exec, logs, port-forward) уязвимы к MITM",
+			Recommendation: "Задать --kubelet-certificate-authority и включить serverTLSBootstrap на kubelet",
+		})
+	}
+	return out
+}
+
+// etcdConfig holds etcd settings from flags and ETCD_* environment variables.
+type etcdConfig struct {
+	values map[string]string
+	source map[string]string // setting -> "--flag" or "ETCD_VAR"
+}
+
+// etcdSettings merges the container's literal ETCD_* variables with its flags; flags
+// take precedence, as in etcd itself.
+func etcdSettings(c k8s.Container, flags map[string]string) etcdConfig {
+	cfg := etcdConfig{values: map[string]string{}, source: map[string]string{}}
+	for _, ev := range c.Env {
+		name, ok := strings.CutPrefix(ev.Name, "ETCD_")
+		if !ok || ev.ValueFrom != nil {
+			continue
+		}
+		key := strings.ReplaceAll(strings.ToLower(name), "_", "-")
+		cfg.values[key], cfg.source[key] = ev.Value, ev.Name
+	}
+	for k, v := range flags {
+		cfg.values[k], cfg.source[k] = v, "--"+k
+	}
+	return cfg
+}
+
+func (e etcdConfig) enabled(name string) bool {
+	b, _ := strconv.ParseBool(e.values[name])
+	return b
+}
+
+// show renders a setting with where it came from, or its default.
+func (e etcdConfig) show(name, def string) string {
+	if src, ok := e.source[name]; ok {
+		return src + "=" + e.values[name]
+	}
+	return "--" + name + "=" + def + " (default)"
+}
+
+func detectEtcdFlags(res model.ResourceRef, cfg etcdConfig) []model.Finding {
+	// with a config file etcd ignores flags and ETCD_* variables, and the file is not visible via the API
+	if file, ok := cfg.values["config-file"]; ok {
+		return []model.Finding{{
+			CheckID:        "K8S-CP-008",
+			Severity:       model.SeverityLow,
+			Resource:       res,
+			Title:          "Настройки TLS etcd заданы в конфигурационном файле и не проверены",
+			Evidence:       fmt.Sprintf("etcd: %s; client-cert-auth, peer-client-cert-auth and auto-tls are read from the file", cfg.show("config-file", "")),
+			Risk:           "Если в файле не включена проверка клиентских сертификатов, любой, кто достучится до порта 2379, читает и меняет все объекты кластера",
+			Recommendation: fmt.Sprintf("Проверить на узле, что в %s заданы client-transport-security.client-cert-auth: true и peer-transport-security.client-cert-auth: true без auto-tls", file),
+		}}
+	}
+
+	var out []model.Finding
+	if !cfg.enabled("client-cert-auth") {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-008",
+			Severity:       model.SeverityCritical,
+			Resource:       res,
+			Title:          "etcd не требует клиентских сертификатов",
+			Evidence:       "etcd: " + cfg.show("client-cert-auth", "false"),
+			Risk:           "Любой, кто достучится до порта 2379, читает и меняет все объекты кластера, включая Secret",
+			Recommendation: "Установить --client-cert-auth=true и --trusted-ca-file",
+		})
+	}
+	var weak []string
+	if !cfg.enabled("peer-client-cert-auth") {
+		weak = append(weak, cfg.show("peer-client-cert-auth", "false"))
+	}
+	for _, f := range []string{"auto-tls", "peer-auto-tls"} {
+		if cfg.enabled(f) {
+			weak = append(weak, cfg.show(f, "false"))
+		}
+	}
+	if len(weak) > 0 {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-CP-008",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Слабая TLS-аутентификация etcd",
+			Evidence:       "etcd: " + strings.Join(weak, "; "),
+			Risk:           "Самоподписанные сертификаты и отсутствие проверки peer позволяют подключиться к кластеру etcd постороннему узлу",
+			Recommendation: "Установить --peer-client-cert-auth=true и не использовать --auto-tls/--peer-auto-tls",
+		})
+	}
+	return out
+}
+
+// controlPlaneComponent identifies the component by the kubeadm "component" label or the binary name.
+func controlPlaneComponent(p k8s.Pod, c k8s.Container) string {
+	if len(c.Command) > 0 {
+		if bin := path.Base(c.Command[0]); containsAny(controlPlaneComponents, bin) {
+			return bin
+		}
+	}
+	if comp := p.Metadata.Labels["component"]; containsAny(controlPlaneComponents, comp) && containsAny(controlPlaneComponents, c.Name) {
+		return comp
+	}
+	return ""
+}
+
+// parseFlags reads "--name=value", "--name value" and bare boolean "--name" arguments.
+func parseFlags(args []string) map[string]string {
+	flags := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+		a = strings.TrimLeft(a, "-")
+		if name, value, ok := strings.Cut(a, "="); ok {
+			flags[name] = value
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flags[a] = args[i+1]
+			i++
+			continue
+		}
+		flags[a] = "true"
+	}
+	return flags
+}
+
+func flagValue(flags map[string]string, name, def string) string {
+	if v, ok := flags[name]; ok {
+		return v
+	}
+	return def + " (default)"
+}
+
+func splitFlagList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/secretscan"
+)
+
+// DetectPlaintextCredentials looks for credentials hardcoded in env values, command and args.
+func DetectPlaintextCredentials(pods []k8s.Pod) []model.Finding {
+	var out []model.Finding
+	for _, p := range pods {
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			var matches []secretscan.Match
+			for _, ev := range c.Env {
+				if ev.ValueFrom != nil {
+					continue
+				}
+				for _, m := range secretscan.ScanValue(ev.Name, ev.Value) {
+					m.Key = "env " + ev.Name
+					matches = append(matches, m)
+				}
+			}
+			for _, m := range secretscan.ScanArgs(c.Command) {
+				m.Key = "command " + m.Key
+				matches = append(matches, m)
+			}
+			for _, m := range secretscan.ScanArgs(c.Args) {
+				m.Key = "args " + m.Key
+				matches = append(matches, m)
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-POD-013",
+				Severity:       credentialSeverity(matches),
+				Resource:       model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name},
+				Title:          "Учетные данные в открытом виде в спецификации контейнера",
+				Evidence:       truncateEvidence(fmt.Sprintf("container %q: %s", c.Name, joinMatches(matches)), 1024),
+				Risk:           "Спецификация Pod'а читается всеми с get/list pods и попадает в etcd, GitOps-репозитории и логи без шифрования",
+				Recommendation: "Перенести значения в Secret (secretKeyRef или volume) и отозвать/ротировать раскрытые учетные данные",
+			})
+		}
+	}
+	return out
+}
+
+func credentialSeverity(ms []secretscan.Match) model.Severity {
+	max := secretscan.Possible
+	for _, m := range ms {
+		if m.Confidence > max {
+			max = m.Confidence
+		}
+	}
+	switch max {
+	case secretscan.Certain:
+		return model.SeverityHigh
+	case secretscan.Likely:
+		return model.SeverityMedium
+	}
+	return model.SeverityLow
+}
+
+func joinMatches(ms []secretscan.Match) string {
+	parts := make([]string, 0, len(ms))
+	for _, m := range ms {
+		parts = append(parts, m.String())
+	}
+	return strings.Join(parts, "; ")
+}
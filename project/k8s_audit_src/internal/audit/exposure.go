@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Exposure graph: entry point (Ingress/LB/NodePort) -> Service -> Pods.
+
+type entryPoint struct {
+	Kind    string
+	Name    string
+	Service string
+}
+
+func (e entryPoint) String() string {
+	if e.Kind == "Service" {
+		return "Service/" + e.Name
+	}
+	return fmt.Sprintf("%s/%s -> Service/%s", e.Kind, e.Name, e.Service)
+}
+
+// DetectExposedWorkloads correlates internet-facing entry points with risky pods behind them.
+func DetectExposedWorkloads(pods []k8s.Pod, svcs []k8s.Service, ing []k8s.Ingress, saIndex map[string]k8s.ServiceAccount, e EffectiveRBAC) []model.Finding {
+	exposed := exposedPods(pods, svcs, ing)
+
+	var out []model.Finding
+	for _, p := range pods {
+		pk := p.Metadata.Namespace + "/" + p.Metadata.Name
+		eps, ok := exposed[pk]
+		if !ok {
+			continue
+		}
+		critical, high, medium := podExposureRisks(p, saIndex, e)
+		if len(critical) == 0 && len(high) == 0 && len(medium) == 0 {
+			continue
+		}
+		sev := model.SeverityMedium
+		switch {
+		case len(critical) > 0:
+			sev = model.SeverityCritical
+		case len(high) > 0:
+			sev = model.SeverityHigh
+		}
+		paths := make([]string, 0, len(eps))
+		for _, ep := range eps {
+			paths = append(paths, ep.String())
+		}
+		sort.Strings(paths)
+		out = append(out, model.Finding{
+			CheckID:        "K8S-EXP-001",
+			Severity:       sev,
+			Resource:       model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name},
+			Title:          "Внешне доступный Pod с опасной конфигурацией",
+			Evidence:       fmt.Sprintf("exposed via %s; risks: %s", strings.Join(uniqStrings(paths), ", "), strings.Join(append(append(critical, high...), medium...), "; ")),
+			Risk:           "RCE во внешнем сервисе сразу дает атакующему доступ к узлу или kube-API",
+			Recommendation: "Убрать привилегии у опубликованного workload или вынести опасные функции в отдельный непубличный Pod",
+		})
+	}
+	return out
+}
+
+// exposedPods maps namespace/pod to the internet-facing entry points routing to it.
+func exposedPods(pods []k8s.Pod, svcs []k8s.Service, ing []k8s.Ingress) map[string][]entryPoint {
+	svcIndex := map[string]k8s.Service{}
+	for _, s := range svcs {
+		svcIndex[s.Metadata.Namespace+"/"+s.Metadata.Name] = s
+	}
+
+	// namespace/service -> entry points that route to it
+	entries := map[string][]entryPoint{}
+	for _, s := range svcs {
+		typ := strings.ToLower(s.Spec.Type)
+		external := typ == "nodeport" || len(s.Spec.ExternalIPs) > 0 ||
+			(typ == "loadbalancer" && !isInternalLB(s.Metadata.Annotations))
+		if external {
+			key := s.Metadata.Namespace + "/" + s.Metadata.Name
+			entries[key] = append(entries[key], entryPoint{Kind: "Service", Name: s.Metadata.Name + " (" + s.Spec.Type + ")"})
+		}
+	}
+	for _, ig := range ing {
+		for _, svc := range ingressServices(ig) {
+			key := ig.Metadata.Namespace + "/" + svc
+			entries[key] = append(entries[key], entryPoint{Kind: "Ingress", Name: ig.Metadata.Name, Service: svc})
+		}
+	}
+
+	// pod -> entry points
+	exposed := map[string][]entryPoint{}
+	for key, eps := range entries {
+		s, ok := svcIndex[key]
+		if !ok {
+			continue
+		}
+		for _, p := range selectPods(pods, s.Metadata.Namespace, s.Spec.Selector) {
+			pk := p.Metadata.Namespace + "/" + p.Metadata.Name
+			exposed[pk] = append(exposed[pk], eps...)
+		}
+	}
+	return exposed
+}
+
+// podExposureRisks splits pod risks into node/cluster takeover (critical), root (high) and
+// possible root through the image default user (medium).
+func podExposureRisks(p k8s.Pod, saIndex map[string]k8s.ServiceAccount, e EffectiveRBAC) (critical, high, medium []string) {
+	containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+	containers = append(containers, p.Spec.Containers...)
+	for _, c := range containers {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+			critical = append(critical, fmt.Sprintf("privileged container %q", c.Name))
+		}
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath == nil {
+			continue
+		}
+		switch sev, class, _ := hostPathRisk(p, v); sev {
+		case model.SeverityCritical:
+			critical = append(critical, fmt.Sprintf("hostPath %q (%s)", v.HostPath.Path, class))
+		case model.SeverityHigh:
+			high = append(high, fmt.Sprintf("hostPath %q (%s)", v.HostPath.Path, class))
+		}
+	}
+	if tokenAutomounted(p, saIndex) {
+		sa := podServiceAccount(p)
+		if grants := dangerousGrants(e.BySA[p.Metadata.Namespace+"/"+sa]); len(grants) > 0 {
+			critical = append(critical, fmt.Sprintf("token of SA %q with %s", sa, strings.Join(grants, ", ")))
+		}
+	}
+	root, imageDefault := rootContainers(p)
+	for _, c := range root {
+		high = append(high, fmt.Sprintf("container %q runs as root", c))
+	}
+	for _, c := range imageDefault {
+		medium = append(medium, fmt.Sprintf("container %q may run as root (image default user: no runAsUser/runAsNonRoot)", c))
+	}
+	return critical, high, medium
+}
+
+// rootContainers returns containers whose effective runAsUser is 0 or runAsNonRoot=false,
+// and separately those with neither set, which run as the image's (often root) user.
+func rootContainers(p k8s.Pod) (root, imageDefault []string) {
+	var podUser *int64
+	var podNonRoot *bool
+	if p.Spec.SecurityContext != nil {
+		podUser = p.Spec.SecurityContext.RunAsUser
+		podNonRoot = p.Spec.SecurityContext.RunAsNonRoot
+	}
+	containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+	containers = append(containers, p.Spec.Containers...)
+	for _, c := range containers {
+		user, nonRoot := podUser, podNonRoot
+		if c.SecurityContext != nil {
+			if c.SecurityContext.RunAsUser != nil {
+				user = c.SecurityContext.RunAsUser
+			}
+			if c.SecurityContext.RunAsNonRoot != nil {
+				nonRoot = c.SecurityContext.RunAsNonRoot
+			}
+		}
+		switch {
+		case (user != nil && *user == 0) || (user == nil && nonRoot != nil && !*nonRoot):
+			root = append(root, c.Name)
+		case user == nil && nonRoot == nil:
+			imageDefault = append(imageDefault, c.Name)
+		}
+	}
+	return root, imageDefault
+}
+
+func ingressServices(ig k8s.Ingress) []string {
+	var out []string
+	if b := ig.Spec.DefaultBackend; b != nil && b.Service != nil {
+		out = append(out, b.Service.Name)
+	}
+	for _, r := range ig.Spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+		for _, p := range r.HTTP.Paths {
+			if p.Backend.Service != nil {
+				out = append(out, p.Backend.Service.Name)
+			}
+		}
+	}
+	return uniqStrings(out)
+}
+
+// selectPods returns pods in ns matching a Service-style selector (empty selects nothing).
+func selectPods(pods []k8s.Pod, ns string, sel map[string]string) []k8s.Pod {
+	if len(sel) == 0 {
+		return nil
+	}
+	var out []k8s.Pod
+	for _, p := range pods {
+		if p.Metadata.Namespace == ns && labelsMatch(sel, p.Metadata.Labels) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
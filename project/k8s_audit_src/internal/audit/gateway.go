@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// GatewayAPI groups gateway.networking.k8s.io objects (all empty if the CRDs are absent).
+type GatewayAPI struct {
+	Gateways        []k8s.Gateway
+	HTTPRoutes      []k8s.HTTPRoute
+	TLSRoutes       []k8s.TLSRoute
+	ReferenceGrants []k8s.ReferenceGrant
+}
+
+func detectGatewayAPI(gw GatewayAPI) []model.Finding {
+	var out []model.Finding
+
+	for _, g := range gw.Gateways {
+		ref := model.ResourceRef{Kind: "Gateway", Namespace: g.Metadata.Namespace, Name: g.Metadata.Name}
+		for _, l := range g.Spec.Listeners {
+			proto := strings.ToUpper(l.Protocol)
+			if proto == "HTTP" || proto == "TCP" || proto == "UDP" {
+				host := "*"
+				if l.Hostname != nil {
+					host = *l.Hostname
+				}
+				out = append(out, model.Finding{
+					CheckID:        "K8S-GW-001",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "Listener Gateway без TLS",
+					Evidence:       fmt.Sprintf("listener %q: protocol=%s port=%d hostname=%s", l.Name, l.Protocol, l.Port, host),
+					Risk:           "Трафик к опубликованным маршрутам передается в открытом виде",
+					Recommendation: "Использовать протокол HTTPS/TLS с certificateRefs и редирект с HTTP",
+				})
+			}
+			if l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil && strings.EqualFold(l.AllowedRoutes.Namespaces.From, "All") {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-GW-002",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "Gateway принимает маршруты из всех namespace",
+					Evidence:       fmt.Sprintf("listener %q: allowedRoutes.namespaces.from=All", l.Name),
+					Risk:           "Любой, кто может создать HTTPRoute в любом namespace, публикует сервисы и перехватывает hostnames",
+					Recommendation: "Использовать from: Same или Selector с явным списком namespace",
+				})
+			}
+		}
+	}
+
+	for _, rg := range gw.ReferenceGrants {
+		ref := model.ResourceRef{Kind: "ReferenceGrant", Namespace: rg.Metadata.Namespace, Name: rg.Metadata.Name}
+		from := []string{}
+		for _, f := range rg.Spec.From {
+			from = append(from, fmt.Sprintf("%s/%s", f.Namespace, f.Kind))
+		}
+		for _, t := range rg.Spec.To {
+			if t.Name != nil && *t.Name != "" {
+				continue
+			}
+			switch t.Kind {
+			case "Secret":
+				out = append(out, model.Finding{
+					CheckID:        "K8S-GW-003",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "ReferenceGrant открывает все Secret namespace",
+					Evidence:       fmt.Sprintf("from=%v to kind=Secret (no name)", from),
+					Risk:           "Объекты из другого namespace могут ссылаться на любой Secret (например, чужие TLS-ключи)",
+					Recommendation: "Указать to[].name для конкретного Secret",
+				})
+			case "Service":
+				out = append(out, model.Finding{
+					CheckID:        "K8S-GW-003",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "ReferenceGrant открывает все Service namespace",
+					Evidence:       fmt.Sprintf("from=%v to kind=%s (no name)", from, t.Kind),
+					Risk:           "Маршруты из другого namespace могут публиковать любой сервис этого namespace",
+					Recommendation: "Указать to[].name для конкретного Service",
+				})
+			}
+		}
+	}
+
+	for _, r := range gw.HTTPRoutes {
+		var paths []string
+		var backends []k8s.GatewayObjectRef
+		for _, rule := range r.Spec.Rules {
+			for _, m := range rule.Matches {
+				if m.Path != nil {
+					paths = append(paths, m.Path.Value)
+				}
+			}
+			backends = append(backends, rule.BackendRefs...)
+		}
+		ref := model.ResourceRef{Kind: "HTTPRoute", Namespace: r.Metadata.Namespace, Name: r.Metadata.Name}
+		out = append(out, detectRouteBackends(ref, r.Spec.Hostnames, paths, backends)...)
+	}
+	for _, r := range gw.TLSRoutes {
+		var backends []k8s.GatewayObjectRef
+		for _, rule := range r.Spec.Rules {
+			backends = append(backends, rule.BackendRefs...)
+		}
+		ref := model.ResourceRef{Kind: "TLSRoute", Namespace: r.Metadata.Namespace, Name: r.Metadata.Name}
+		out = append(out, detectRouteBackends(ref, r.Spec.Hostnames, nil, backends)...)
+	}
+	return out
+}
+
+func detectRouteBackends(ref model.ResourceRef, hostnames, paths []string, backends []k8s.GatewayObjectRef) []model.Finding {
+	var out []model.Finding
+	for _, b := range backends {
+		if b.Kind != "" && b.Kind != "Service" {
+			continue
+		}
+		ns := b.Namespace
+		if ns == "" {
+			ns = ref.Namespace
+		}
+		target := fmt.Sprintf("Service/%s/%s:%d", ns, b.Name, b.Port)
+		if sp, ok := sensitivePorts[b.Port]; ok {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-GW-004",
+				Severity:       sp.Severity,
+				Resource:       ref,
+				Title:          fmt.Sprintf("Маршрут публикует порт %s", sp.Service),
+				Evidence:       fmt.Sprintf("hostnames=%v backend=%s", hostnames, target),
+				Risk:           "Административные и data-порты часто не требуют аутентификации",
+				Recommendation: "Не публиковать этот backend через Gateway",
+			})
+		}
+		if ns == "kube-system" {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-GW-004",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Маршрут публикует сервис из kube-system",
+				Evidence:       fmt.Sprintf("hostnames=%v backend=%s", hostnames, target),
+				Risk:           "Системные компоненты кластера становятся доступны извне",
+				Recommendation: "Убрать маршрут или ограничить доступ к нему",
+			})
+		}
+	}
+	admin := []string{}
+	for _, p := range paths {
+		if isAdminPath(p) {
+			admin = append(admin, p)
+		}
+	}
+	if len(admin) > 0 {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-GW-004",
+			Severity:       model.SeverityMedium,
+			Resource:       ref,
+			Title:          "Маршрут публикует административные пути",
+			Evidence:       fmt.Sprintf("hostnames=%v paths=%v", hostnames, uniqStrings(admin)),
+			Risk:           "Админ-панели и debug-эндпоинты доступны через Gateway",
+			Recommendation: "Закрыть пути аутентификацией (policy attachment / ext-auth) или не публиковать их",
+		})
+	}
+	return out
+}
@@ -43,3 +43,22 @@ func hasStar(list []string) bool {
 	}
 	return false
 }
+
+// labelsMatch reports whether every key/value in sel is present in labels.
+func labelsMatch(sel, labels map[string]string) bool {
+	for k, v := range sel {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+var systemNamespaces = []string{
+	"kube-system", "kube-public", "kube-node-lease",
+	"istio-system", "linkerd", "linkerd-viz", "linkerd-cni", "cilium", "calico-system", "tigera-operator",
+}
+
+func isSystemNamespace(ns string) bool {
+	return containsAny(systemNamespaces, ns)
+}
@@ -0,0 +1,220 @@
+package audit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+type hostPathClass int
+
+const (
+	hostPathOther hostPathClass = iota
+	hostPathSensitive
+	hostPathEscape
+	hostPathTakeover
+)
+
+func (c hostPathClass) String() string {
+	switch c {
+	case hostPathTakeover:
+		return "node takeover"
+	case hostPathEscape:
+		return "container escape"
+	case hostPathSensitive:
+		return "sensitive host data"
+	}
+	return "host filesystem"
+}
+
+type hostPathRule struct {
+	path    string
+	class   hostPathClass
+	subtree bool // paths below inherit the class
+}
+
+// Mounting an ancestor of a listed path exposes it as well ("/" exposes everything).
+var hostPathRules = []hostPathRule{
+	{"/etc", hostPathTakeover, false},
+	{"/etc/kubernetes", hostPathTakeover, true},
+	{"/root", hostPathTakeover, true},
+	{"/proc", hostPathTakeover, true},
+	{"/boot", hostPathTakeover, true},
+	{"/dev", hostPathTakeover, false},
+	{"/usr", hostPathTakeover, false},
+	{"/bin", hostPathTakeover, false},
+	{"/sbin", hostPathTakeover, false},
+	{"/lib", hostPathTakeover, false},
+	{"/var/lib/kubelet", hostPathTakeover, true},
+	{"/var/lib/etcd", hostPathTakeover, true},
+	{"/var/lib/docker", hostPathTakeover, true},
+	{"/var/lib/containerd", hostPathTakeover, true},
+	{"/opt/cni/bin", hostPathTakeover, true},
+	{"/etc/cni", hostPathTakeover, true},
+	{"/var/run/docker.sock", hostPathEscape, false},
+	{"/run/docker.sock", hostPathEscape, false},
+	{"/run/containerd/containerd.sock", hostPathEscape, false},
+	{"/var/run/containerd/containerd.sock", hostPathEscape, false},
+	{"/var/run/crio/crio.sock", hostPathEscape, false},
+	{"/run/crio/crio.sock", hostPathEscape, false},
+	{"/var/run/cri-dockerd.sock", hostPathEscape, false},
+	{"/sys", hostPathSensitive, true},
+	{"/home", hostPathSensitive, true},
+	{"/var/log", hostPathSensitive, false},
+	{"/var/lib/cni", hostPathSensitive, true},
+	{"/run/secrets", hostPathSensitive, true},
+}
+
+var runtimeSockets = []string{"docker.sock", "containerd.sock", "crio.sock", "cri-dockerd.sock", "podman.sock"}
+
+// classifyHostPath rates a host path by what a container gains from it.
+func classifyHostPath(p, typ string) hostPathClass {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return hostPathTakeover
+	}
+	if containsAny(runtimeSockets, path.Base(p)) {
+		return hostPathEscape
+	}
+	if typ == "BlockDevice" {
+		return hostPathTakeover
+	}
+	best := hostPathOther
+	for _, r := range hostPathRules {
+		match := p == r.path || strings.HasPrefix(r.path, p+"/") || (r.subtree && strings.HasPrefix(p, r.path+"/"))
+		if match && r.class > best {
+			best = r.class
+		}
+	}
+	if best < hostPathSensitive && (typ == "CharDevice" || typ == "Socket") {
+		best = hostPathSensitive
+	}
+	return best
+}
+
+type hostPathMount struct {
+	container string
+	mount     k8s.VolumeMount
+}
+
+// hostPathRisk rates a hostPath volume by the mounts that actually use it.
+func hostPathRisk(p k8s.Pod, v k8s.Volume) (model.Severity, hostPathClass, []hostPathMount) {
+	containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+	containers = append(containers, p.Spec.Containers...)
+	var mounts []hostPathMount
+	sev, class := model.Severity(""), hostPathOther
+	for _, c := range containers {
+		for _, m := range c.VolumeMounts {
+			if m.Name != v.Name {
+				continue
+			}
+			effective := v.HostPath.Path
+			if m.SubPath != "" {
+				effective = path.Join(effective, m.SubPath)
+			}
+			cl := classifyHostPath(effective, v.HostPath.Type)
+			s := hostPathSeverity(cl)
+			// a read-only socket can still be connected to
+			if m.ReadOnly && cl != hostPathEscape {
+				s = lowerSeverity(s)
+			}
+			if m.MountPropagation == "Bidirectional" {
+				s = raiseSeverity(s)
+			}
+			mounts = append(mounts, hostPathMount{container: c.Name, mount: m})
+			if model.SeverityRank(s) > model.SeverityRank(sev) {
+				sev = s
+			}
+			if cl > class {
+				class = cl
+			}
+		}
+	}
+	if len(mounts) == 0 {
+		return model.SeverityLow, classifyHostPath(v.HostPath.Path, v.HostPath.Type), nil
+	}
+	return sev, class, mounts
+}
+
+func hostPathSeverity(c hostPathClass) model.Severity {
+	switch c {
+	case hostPathTakeover, hostPathEscape:
+		return model.SeverityCritical
+	case hostPathSensitive:
+		return model.SeverityHigh
+	}
+	return model.SeverityMedium
+}
+
+func lowerSeverity(s model.Severity) model.Severity {
+	switch s {
+	case model.SeverityCritical:
+		return model.SeverityHigh
+	case model.SeverityHigh:
+		return model.SeverityMedium
+	}
+	return model.SeverityLow
+}
+
+func detectHostPaths(p k8s.Pod) []model.Finding {
+	var out []model.Finding
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath == nil {
+			continue
+		}
+		sev, class, mounts := hostPathRisk(p, v)
+		ev := fmt.Sprintf("volume %q: hostPath=%q", v.Name, v.HostPath.Path)
+		if v.HostPath.Type != "" {
+			ev += " type=" + v.HostPath.Type
+		}
+		ev += " (" + class.String() + ")"
+		if len(mounts) == 0 {
+			ev += "; not mounted by any container"
+		} else {
+			parts := make([]string, 0, len(mounts))
+			for _, m := range mounts {
+				mode := "rw"
+				if m.mount.ReadOnly {
+					mode = "ro"
+				}
+				s := fmt.Sprintf("%s:%s %s", m.container, m.mount.MountPath, mode)
+				if m.mount.SubPath != "" {
+					s += " subPath=" + m.mount.SubPath
+				}
+				if m.mount.MountPropagation != "" && m.mount.MountPropagation != "None" {
+					s += " propagation=" + m.mount.MountPropagation
+				}
+				parts = append(parts, s)
+			}
+			ev += "; mounts: " + strings.Join(parts, ", ")
+		}
+		if strings.HasSuffix(v.HostPath.Type, "OrCreate") {
+			ev += "; may create the path on the node"
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-002",
+			Severity:       sev,
+			Resource:       model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name},
+			Title:          "Монтирование hostPath",
+			Evidence:       ev,
+			Risk:           hostPathRiskText(class),
+			Recommendation: "Избегать hostPath. Использовать PVC/CSI или минимально необходимый путь + readOnly",
+		})
+	}
+	return out
+}
+
+func hostPathRiskText(c hostPathClass) string {
+	switch c {
+	case hostPathTakeover:
+		return "Запись или чтение системных путей узла (конфигурация, kubelet, /proc) дает полный контроль над узлом"
+	case hostPathEscape:
+		return "Доступ к сокету container runtime позволяет запустить привилегированный контейнер на узле"
+	case hostPathSensitive:
+		return "Доступ к данным узла (логи, /sys, домашние каталоги) раскрывает чужие данные и облегчает эскалацию"
+	}
+	return "Доступ к ФС узла может привести к эскалации привилегий и утечке данных"
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+func DetectImages(pods []k8s.Pod, allowedRegistries []string) []model.Finding {
+	var out []model.Finding
+	// mutable image reference -> running digest -> pods
+	running := map[string]map[string][]string{}
+
+	for _, p := range pods {
+		ns := p.Metadata.Namespace
+		name := p.Metadata.Name
+		statuses := map[string]k8s.ContainerStatus{}
+		for _, st := range append(append([]k8s.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...) {
+			statuses[st.Name] = st
+		}
+
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, ctn := range containers {
+			if ctn.Image == "" {
+				continue
+			}
+			ref := imageref.Parse(ctn.Image)
+
+			if ref.Digest == "" && (ref.Tag == "" || ref.Tag == "latest") {
+				ev := fmt.Sprintf("container %q: image=%q (implicit :latest)", ctn.Name, ctn.Image)
+				if ref.Tag == "latest" {
+					ev = fmt.Sprintf("container %q: image=%q", ctn.Name, ctn.Image)
+				}
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-001",
+					Severity:       model.SeverityMedium,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "Образ с тегом latest или без тега",
+					Evidence:       ev,
+					Risk:           "Содержимое образа меняется без изменения манифеста; невозможно воспроизвести и проверить, что запущено",
+					Recommendation: "Использовать фиксированный тег версии и digest (image@sha256:...)",
+				})
+			} else if ref.Digest == "" {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-002",
+					Severity:       model.SeverityLow,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "Образ не закреплен по digest",
+					Evidence:       fmt.Sprintf("container %q: image=%q", ctn.Name, ctn.Image),
+					Risk:           "Тег может быть перезаписан в registry (в т.ч. атакующим с доступом к registry)",
+					Recommendation: "Указывать образ как image:tag@sha256:<digest>",
+				})
+			}
+
+			if len(allowedRegistries) > 0 && !registryAllowed(ref, allowedRegistries) {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-003",
+					Severity:       model.SeverityHigh,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "Образ из registry вне allowlist",
+					Evidence:       fmt.Sprintf("container %q: image=%q registry=%s allowed=%v", ctn.Name, ctn.Image, ref.Registry, allowedRegistries),
+					Risk:           "Образы из непроверенных источников могут содержать вредоносный код",
+					Recommendation: "Зеркалировать образ в доверенный registry и запретить прочие admission-политикой",
+				})
+			}
+
+			switch {
+			case strings.EqualFold(ctn.ImagePullPolicy, "Never"):
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-004",
+					Severity:       model.SeverityMedium,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "imagePullPolicy=Never",
+					Evidence:       fmt.Sprintf("container %q: image=%q imagePullPolicy=Never", ctn.Name, ctn.Image),
+					Risk:           "Используется любой образ с этим именем из кэша узла, включая подложенный на узел",
+					Recommendation: "Использовать Always или IfNotPresent с digest",
+				})
+			case strings.EqualFold(ctn.ImagePullPolicy, "IfNotPresent") && ref.Mutable():
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-004",
+					Severity:       model.SeverityLow,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "IfNotPresent для изменяемого тега",
+					Evidence:       fmt.Sprintf("container %q: image=%q imagePullPolicy=IfNotPresent, no digest", ctn.Name, ctn.Image),
+					Risk:           "Узлы запускают устаревшую или подмененную копию из кэша; без AlwaysPullImages образ доступен без pull-секрета",
+					Recommendation: "Закрепить образ по digest или использовать imagePullPolicy: Always",
+				})
+			}
+
+			st, ok := statuses[ctn.Name]
+			if !ok {
+				continue
+			}
+			runDigest := imageref.DigestFromImageID(st.ImageID)
+			if ref.Digest != "" && runDigest != "" && runDigest != ref.Digest {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-IMG-005",
+					Severity:       model.SeverityHigh,
+					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
+					Title:          "Запущенный образ не совпадает с digest в спецификации",
+					Evidence:       fmt.Sprintf("container %q: spec digest=%s running imageID=%s", ctn.Name, ref.Digest, st.ImageID),
+					Risk:           "Узел запустил иное содержимое, чем задекларировано (подмена образа или кэша)",
+					Recommendation: "Проверить узел и registry, пересоздать Pod",
+				})
+			}
+			if ref.Mutable() && runDigest != "" {
+				key := ref.String()
+				if running[key] == nil {
+					running[key] = map[string][]string{}
+				}
+				running[key][runDigest] = append(running[key][runDigest], ns+"/"+name)
+			}
+		}
+	}
+
+	for img, digests := range running {
+		if len(digests) < 2 {
+			continue
+		}
+		parts := []string{}
+		for d, ps := range digests {
+			sort.Strings(ps)
+			parts = append(parts, fmt.Sprintf("%s: %s", shortDigest(d), strings.Join(uniqStrings(ps), ",")))
+		}
+		sort.Strings(parts)
+		out = append(out, model.Finding{
+			CheckID:        "K8S-IMG-005",
+			Severity:       model.SeverityMedium,
+			Resource:       model.ResourceRef{Kind: "Image", Name: img},
+			Title:          "Один тег образа запущен с разными digest",
+			Evidence:       strings.Join(parts, "; "),
+			Risk:           "Тег был перезаписан: часть Pod'ов работает на устаревшем или подмененном образе",
+			Recommendation: "Закрепить образ по digest и перезапустить workload",
+		})
+	}
+	return out
+}
+
+// registryAllowed matches registry or registry/path prefixes, e.g. "ghcr.io/acme".
+func registryAllowed(ref imageref.Ref, allowed []string) bool {
+	name := ref.Name()
+	for _, a := range allowed {
+		a = strings.TrimSuffix(strings.TrimSpace(a), "/")
+		if a == "" {
+			continue
+		}
+		if ref.Registry == a || strings.HasPrefix(name, a+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func shortDigest(d string) string {
+	d = strings.TrimPrefix(d, "sha256:")
+	if len(d) > 12 {
+		d = d[:12]
+	}
+	return "sha256:" + d
+}
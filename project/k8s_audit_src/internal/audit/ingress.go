@@ -0,0 +1,253 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// ingress-nginx annotations.
+
+const nginxAnnotationPrefix = "nginx.ingress.kubernetes.io/"
+
+// Snippet annotations are pasted into nginx.conf verbatim (CVE-2021-25742).
+var nginxSnippetAnnotations = []string{
+	"configuration-snippet",
+	"server-snippet",
+	"auth-snippet",
+	"stream-snippet",
+	"modsecurity-snippet",
+}
+
+// Annotations that count as access control in front of admin paths.
+var ingressAuthAnnotations = []string{
+	nginxAnnotationPrefix + "auth-url",
+	nginxAnnotationPrefix + "auth-type",
+	nginxAnnotationPrefix + "auth-tls-secret",
+	nginxAnnotationPrefix + "whitelist-source-range",
+	nginxAnnotationPrefix + "allowlist-source-range",
+	"traefik.ingress.kubernetes.io/router.middlewares",
+	"haproxy.org/auth-type",
+	"haproxy.org/allow-list",
+	"haproxy.org/whitelist",
+}
+
+var adminPathMarkers = []string{
+	"admin", "dashboard", "console", "manage", "actuator", "debug",
+	"metrics", "swagger", "phpmyadmin", "grafana", "kibana", "jenkins",
+	"prometheus", "graphql", "internal",
+}
+
+func detectIngresses(ing []k8s.Ingress) []model.Finding {
+	var out []model.Finding
+	for _, ig := range ing {
+		ref := model.ResourceRef{Kind: "Ingress", Namespace: ig.Metadata.Namespace, Name: ig.Metadata.Name}
+		ann := ig.Metadata.Annotations
+
+		tlsHosts := []string{}
+		for _, t := range ig.Spec.TLS {
+			tlsHosts = append(tlsHosts, t.Hosts...)
+		}
+		noTLS := []string{}
+		for _, r := range ig.Spec.Rules {
+			h := r.Host
+			if h == "" {
+				h = "*"
+			}
+			if !hostCoveredByTLS(r.Host, tlsHosts, len(ig.Spec.TLS) > 0) {
+				noTLS = append(noTLS, h)
+			}
+		}
+		if len(noTLS) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ING-001",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "Ingress обслуживает хосты без TLS",
+				Evidence:       fmt.Sprintf("hosts without tls: %v", uniqStrings(noTLS)),
+				Risk:           "Трафик (включая учетные данные и cookie) передается в открытом виде",
+				Recommendation: "Добавить spec.tls с сертификатом для каждого host и включить редирект на HTTPS",
+			})
+		}
+
+		for _, r := range ig.Spec.Rules {
+			if r.Host == "" {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-ING-002",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "Правило Ingress без host (catch-all)",
+					Evidence:       "rules[].host is empty",
+					Risk:           "Правило принимает запросы с любым Host, в т.ч. прямые обращения по IP балансировщика",
+					Recommendation: "Указать конкретный host для каждого правила",
+				})
+			} else if strings.HasPrefix(r.Host, "*") {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-ING-002",
+					Severity:       model.SeverityLow,
+					Resource:       ref,
+					Title:          "Wildcard host в правиле Ingress",
+					Evidence:       fmt.Sprintf("rules[].host=%q", r.Host),
+					Risk:           "Wildcard публикует backend для всех поддоменов, включая неучтенные",
+					Recommendation: "Перечислить конкретные хосты вместо wildcard",
+				})
+			}
+		}
+
+		if ig.Spec.IngressClassName == nil && ann["kubernetes.io/ingress.class"] == "" {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ING-003",
+				Severity:       model.SeverityLow,
+				Resource:       ref,
+				Title:          "Не указан ingressClassName",
+				Evidence:       "spec.ingressClassName not set",
+				Risk:           "Ingress может быть подхвачен контроллером по умолчанию или несколькими контроллерами (в т.ч. внешним)",
+				Recommendation: "Явно указать spec.ingressClassName",
+			})
+		}
+
+		if ig.Spec.DefaultBackend != nil {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ING-004",
+				Severity:       model.SeverityLow,
+				Resource:       ref,
+				Title:          "Ingress задает defaultBackend",
+				Evidence:       fmt.Sprintf("spec.defaultBackend=%s", ingressBackendString(*ig.Spec.DefaultBackend)),
+				Risk:           "Весь несопоставленный трафик попадает в backend, который может не ожидать внешних запросов",
+				Recommendation: "Убрать defaultBackend или направить его на сервис-заглушку",
+			})
+		}
+
+		for _, a := range nginxSnippetAnnotations {
+			if v, ok := ann[nginxAnnotationPrefix+a]; ok {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-ING-005",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "ingress-nginx snippet-аннотация",
+					Evidence:       fmt.Sprintf("annotation %s%s (%d bytes)", nginxAnnotationPrefix, a, len(v)),
+					Risk:           "Snippet вставляется в nginx.conf контроллера: возможно чтение токена контроллера и секретов всех namespace (CVE-2021-25742)",
+					Recommendation: "Убрать snippet-аннотации и установить allow-snippet-annotations=false в ConfigMap контроллера",
+				})
+			}
+		}
+
+		if v, ok := ann[nginxAnnotationPrefix+"auth-url"]; ok && strings.ContainsAny(v, "\n\r;{}'\"`\\#") {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ING-006",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Подозрение на инъекцию в auth-url",
+				Evidence:       fmt.Sprintf("annotation %sauth-url contains config metacharacters: %q", nginxAnnotationPrefix, truncateEvidence(v, 120)),
+				Risk:           "Спецсимволы в auth-url позволяют внедрить директивы в конфигурацию nginx (CVE-2023-5043, CVE-2025-24514)",
+				Recommendation: "Указать в auth-url только корректный URL и обновить ingress-nginx",
+			})
+		}
+
+		proto := strings.ToUpper(ann[nginxAnnotationPrefix+"backend-protocol"])
+		if (proto == "HTTPS" || proto == "GRPCS") && !strings.EqualFold(ann[nginxAnnotationPrefix+"proxy-ssl-verify"], "on") {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-ING-007",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "TLS до backend без проверки сертификата",
+				Evidence:       fmt.Sprintf("backend-protocol=%s, proxy-ssl-verify not \"on\"", proto),
+				Risk:           "Контроллер не проверяет сертификат backend, что допускает MITM внутри кластера",
+				Recommendation: "Включить proxy-ssl-verify: \"on\" и задать proxy-ssl-secret с CA",
+			})
+		}
+
+		if !hasAnyAnnotation(ann, ingressAuthAnnotations) {
+			admin := []string{}
+			for _, r := range ig.Spec.Rules {
+				if r.HTTP == nil {
+					continue
+				}
+				for _, p := range r.HTTP.Paths {
+					if isAdminPath(p.Path) {
+						admin = append(admin, r.Host+p.Path)
+					}
+				}
+			}
+			if len(admin) > 0 {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-ING-008",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "Административные пути опубликованы без auth/allowlist",
+					Evidence:       fmt.Sprintf("paths=%v, no auth or source-range annotations", uniqStrings(admin)),
+					Risk:           "Админ-панели и debug-эндпоинты доступны из интернета без ограничения доступа",
+					Recommendation: "Добавить auth-url/auth-type или whitelist-source-range, либо не публиковать эти пути",
+				})
+			}
+		}
+	}
+	return out
+}
+
+// hostCoveredByTLS reports whether host is served by spec.tls.
+// A tls entry without hosts applies to rules without a host.
+func hostCoveredByTLS(host string, tlsHosts []string, hasTLS bool) bool {
+	if !hasTLS {
+		return false
+	}
+	if host == "" {
+		return true
+	}
+	for _, t := range tlsHosts {
+		if strings.EqualFold(t, host) {
+			return true
+		}
+		if strings.HasPrefix(t, "*.") {
+			suffix := strings.ToLower(t[1:])
+			h := strings.ToLower(host)
+			if strings.HasSuffix(h, suffix) && !strings.Contains(strings.TrimSuffix(h, suffix), ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isAdminPath(p string) bool {
+	p = strings.ToLower(p)
+	for _, seg := range strings.Split(p, "/") {
+		for _, m := range adminPathMarkers {
+			if seg == m || strings.HasPrefix(seg, m+"-") || strings.HasPrefix(seg, m+"_") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAnyAnnotation(ann map[string]string, keys []string) bool {
+	for _, k := range keys {
+		if _, ok := ann[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func ingressBackendString(b k8s.IngressBackend) string {
+	if b.Service != nil {
+		if b.Service.Port.Name != "" {
+			return fmt.Sprintf("service/%s:%s", b.Service.Name, b.Service.Port.Name)
+		}
+		return fmt.Sprintf("service/%s:%d", b.Service.Name, b.Service.Port.Number)
+	}
+	if b.Resource != nil {
+		return strings.ToLower(b.Resource.Kind) + "/" + b.Resource.Name
+	}
+	return "(empty)"
+}
+
+func truncateEvidence(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,217 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// safeSysctls is the Pod Security Standards baseline allowlist.
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.tcp_syncookies",
+	"net.ipv4.ping_group_range",
+	"net.ipv4.tcp_keepalive_time",
+	"net.ipv4.tcp_fin_timeout",
+	"net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+}
+
+// namespacedSysctlPrefixes are isolated per pod; anything else is node-wide.
+var namespacedSysctlPrefixes = []string{"kernel.shm", "kernel.msg", "kernel.sem", "fs.mqueue.", "net."}
+
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// SELinux types allowed by the baseline Pod Security Standard.
+var confinedSELinuxTypes = []string{"container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+
+// unconfinedSELinuxTypes run without SELinux confinement; other unknown types are MEDIUM.
+var unconfinedSELinuxTypes = map[string]model.Severity{
+	"spc_t":        model.SeverityCritical,
+	"unconfined_t": model.SeverityHigh,
+}
+
+// detectPodIsolation covers pod-wide fields that weaken isolation besides host namespaces.
+func detectPodIsolation(p k8s.Pod) []model.Finding {
+	res := model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name}
+	psc := p.Spec.SecurityContext
+	var out []model.Finding
+
+	if p.Spec.ShareProcessNamespace != nil && *p.Spec.ShareProcessNamespace {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-017",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Общее пространство процессов контейнеров Pod'а",
+			Evidence:       "spec.shareProcessNamespace=true",
+			Risk:           "Контейнеры видят процессы друг друга и через /proc/<pid>/root читают ФС и секреты соседних контейнеров",
+			Recommendation: "Отключить shareProcessNamespace, если он не нужен для отладки",
+		})
+	}
+	if p.Spec.HostUsers != nil && *p.Spec.HostUsers {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-018",
+			Severity:       model.SeverityLow,
+			Resource:       res,
+			Title:          "User namespace явно отключен",
+			Evidence:       "spec.hostUsers=true",
+			Risk:           "root в контейнере совпадает с root на узле: любой выход из контейнера дает root на узле",
+			Recommendation: "Использовать hostUsers: false там, где поддерживается",
+		})
+	}
+
+	if psc != nil {
+		for _, sc := range psc.Sysctls {
+			if containsAny(safeSysctls, sc.Name) {
+				continue
+			}
+			sev, scope := model.SeverityHigh, "node-wide"
+			if hasAnyPrefix(sc.Name, namespacedSysctlPrefixes) {
+				sev, scope = model.SeverityMedium, "namespaced"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-POD-016",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Небезопасный sysctl",
+				Evidence:       fmt.Sprintf("securityContext.sysctls: %s=%s (%s, not in the safe set)", sc.Name, sc.Value, scope),
+				Risk:           "Небезопасные sysctl меняют параметры ядра, общие с другими Pod'ами или узлом",
+				Recommendation: "Использовать только безопасные sysctl; остальные настраивать на уровне узла",
+			})
+		}
+	}
+
+	return out
+}
+
+// detectContainerIsolation applies pod-level security options unless the container overrides them.
+func detectContainerIsolation(p k8s.Pod, c k8s.Container) []model.Finding {
+	res := model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name}
+	psc := p.Spec.SecurityContext
+	ctx := c.SecurityContext
+	var out []model.Finding
+
+	for _, port := range c.Ports {
+		if port.HostPort == 0 {
+			continue
+		}
+		ip := port.HostIP
+		if ip == "" {
+			ip = "0.0.0.0"
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-014",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "Порт опубликован на узле (hostPort)",
+			Evidence:       fmt.Sprintf("container %q: hostPort=%d -> containerPort=%d/%s on %s", c.Name, port.HostPort, port.ContainerPort, protocolOrTCP(port.Protocol), ip),
+			Risk:           "Контейнер доступен на IP узла в обход Service и, во многих CNI, в обход NetworkPolicy",
+			Recommendation: "Публиковать через Service/Ingress; hostPort использовать только для системных агентов",
+		})
+	}
+
+	if ctx != nil && ctx.ProcMount != nil && strings.EqualFold(*ctx.ProcMount, "Unmasked") {
+		sev := model.SeverityHigh
+		ev := fmt.Sprintf("container %q: securityContext.procMount=Unmasked", c.Name)
+		if p.Spec.HostUsers != nil && !*p.Spec.HostUsers {
+			sev = model.SeverityMedium
+			ev += " (hostUsers=false)"
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-015",
+			Severity:       sev,
+			Resource:       res,
+			Title:          "procMount: Unmasked",
+			Evidence:       ev,
+			Risk:           "Без маскирования /proc доступны /proc/sys, /proc/sysrq-trigger и другие пути для выхода на узел",
+			Recommendation: "Использовать procMount: Default",
+		})
+	}
+
+	var hostProcess *bool
+	if psc != nil && psc.WindowsOptions != nil {
+		hostProcess = psc.WindowsOptions.HostProcess
+	}
+	if ctx != nil && ctx.WindowsOptions != nil && ctx.WindowsOptions.HostProcess != nil {
+		hostProcess = ctx.WindowsOptions.HostProcess
+	}
+	if hostProcess != nil && *hostProcess {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-019",
+			Severity:       model.SeverityCritical,
+			Resource:       res,
+			Title:          "Windows HostProcess контейнер",
+			Evidence:       fmt.Sprintf("container %q: windowsOptions.hostProcess=true", c.Name),
+			Risk:           "HostProcess контейнер работает как процесс на Windows-узле с доступом ко всей системе",
+			Recommendation: "Запрещать hostProcess вне системных namespace (PSS baseline)",
+		})
+	}
+
+	var se *k8s.SELinuxOptions
+	if psc != nil {
+		se = psc.SELinuxOptions
+	}
+	if ctx != nil && ctx.SELinuxOptions != nil {
+		se = ctx.SELinuxOptions
+	}
+	if se != nil && se.Type != "" && !containsAny(confinedSELinuxTypes, se.Type) {
+		sev, ok := unconfinedSELinuxTypes[se.Type]
+		if !ok {
+			sev = model.SeverityMedium
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-020",
+			Severity:       sev,
+			Resource:       res,
+			Title:          "Нестандартный SELinux-тип контейнера",
+			Evidence:       fmt.Sprintf("container %q: seLinuxOptions.type=%s", c.Name, se.Type),
+			Risk:           "spc_t/unconfined_t снимают SELinux-изоляцию контейнера; произвольный тип может быть шире container_t",
+			Recommendation: "Не задавать seLinuxOptions.type или использовать container_t/container_init_t/container_kvm_t",
+		})
+	}
+
+	if src := appArmorUnconfined(p, c); src != "" {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-POD-021",
+			Severity:       model.SeverityMedium,
+			Resource:       res,
+			Title:          "AppArmor отключен для контейнера",
+			Evidence:       fmt.Sprintf("container %q: %s", c.Name, src),
+			Risk:           "Без AppArmor контейнер не ограничен профилем MAC: доступны mount, запись в /proc и др.",
+			Recommendation: "Использовать RuntimeDefault или Localhost профиль AppArmor",
+		})
+	}
+	return out
+}
+
+// appArmorUnconfined returns where the container is made unconfined ("" if it is not).
+func appArmorUnconfined(p k8s.Pod, c k8s.Container) string {
+	if ctx := c.SecurityContext; ctx != nil && ctx.AppArmorProfile != nil {
+		if strings.EqualFold(ctx.AppArmorProfile.Type, "Unconfined") {
+			return "securityContext.appArmorProfile.type=Unconfined"
+		}
+		return ""
+	}
+	if v, ok := p.Metadata.Annotations[appArmorAnnotationPrefix+c.Name]; ok {
+		if v == "unconfined" {
+			return fmt.Sprintf("annotation %s%s=unconfined", appArmorAnnotationPrefix, c.Name)
+		}
+		return ""
+	}
+	if psc := p.Spec.SecurityContext; psc != nil && psc.AppArmorProfile != nil && strings.EqualFold(psc.AppArmorProfile.Type, "Unconfined") {
+		return "pod securityContext.appArmorProfile.type=Unconfined"
+	}
+	return ""
+}
+
+func protocolOrTCP(p string) string {
+	if p == "" {
+		return "TCP"
+	}
+	return p
+}
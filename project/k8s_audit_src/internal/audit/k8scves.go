@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/advisories"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Control-plane components are assumed to run the API server version.
+var serverComponents = []string{"kube-apiserver", "kube-controller-manager"}
+
+type advisoryMatch struct {
+	adv       advisories.Advisory
+	component string
+	fixed     string
+}
+
+// DetectKubernetesCVEs matches the API server gitVersion and node kubelet versions
+// against the advisory database.
+func DetectKubernetesCVEs(db *advisories.DB, serverVersion string, nodes []k8s.Node) []model.Finding {
+	if db == nil {
+		return nil
+	}
+	var out []model.Finding
+	if v, ok := parseVersion(serverVersion); ok {
+		var matches []advisoryMatch
+		for _, c := range serverComponents {
+			matches = append(matches, matchAdvisories(db, c, "linux", v)...)
+		}
+		if len(matches) > 0 {
+			out = append(out, advisoryFinding("K8S-CVE-001", model.ResourceRef{Kind: "Cluster", Name: "control-plane"},
+				"Версия control plane содержит известные уязвимости",
+				"kube-apiserver "+serverVersion, db, matches,
+				"Обновить control plane до %s или новее (для managed-кластеров: до актуальной patch-версии провайдера)"))
+		}
+	}
+
+	for _, n := range nodes {
+		info := n.Status.NodeInfo
+		kubelet, ok := parseVersion(info.KubeletVersion)
+		if !ok {
+			continue
+		}
+		proxy, ok := parseVersion(info.KubeProxyVersion)
+		if !ok {
+			proxy = kubelet // kubeProxyVersion is deprecated and often empty
+		}
+		matches := matchAdvisories(db, "kubelet", info.OperatingSystem, kubelet)
+		matches = append(matches, matchAdvisories(db, "kube-proxy", info.OperatingSystem, proxy)...)
+		if len(matches) == 0 {
+			continue
+		}
+		out = append(out, advisoryFinding("K8S-CVE-002", model.ResourceRef{Kind: "Node", Name: n.Metadata.Name},
+			"Версия kubelet/kube-proxy содержит известные уязвимости",
+			"kubelet "+info.KubeletVersion, db, matches,
+			"Обновить kubelet и kube-proxy узла до %s или новее"))
+	}
+	return out
+}
+
+func matchAdvisories(db *advisories.DB, component, nodeOS string, v [3]int) []advisoryMatch {
+	var out []advisoryMatch
+	for _, a := range db.Kubernetes {
+		if !containsAny(a.Components, component) || (a.OS != "" && nodeOS != "" && !strings.EqualFold(a.OS, nodeOS)) {
+			continue
+		}
+		if fixed, ok := affectedVersion(a, v); ok {
+			out = append(out, advisoryMatch{adv: a, component: component, fixed: fixed})
+		}
+	}
+	return out
+}
+
+// affectedVersion reports whether v is affected and the release that fixes it: the fix on
+// v's branch, or the first later fixed release if the branch got no fix.
+func affectedVersion(a advisories.Advisory, v [3]int) (string, bool) {
+	if intro, ok := parseVersion(a.Introduced); ok && versionLess(v, intro) {
+		return "", false
+	}
+	var next string
+	var nextV [3]int
+	for _, f := range a.Fixed {
+		fv, ok := parseVersion(f)
+		if !ok {
+			continue
+		}
+		if fv[0] == v[0] && fv[1] == v[1] {
+			return f, versionLess(v, fv)
+		}
+		if versionLess(v, fv) && (next == "" || versionLess(fv, nextV)) {
+			next, nextV = f, fv
+		}
+	}
+	return next, next != ""
+}
+
+func advisoryFinding(checkID string, res model.ResourceRef, title, subject string, db *advisories.DB, matches []advisoryMatch, rec string) model.Finding {
+	list, upgradeTo := describeMatches(matches)
+	return model.Finding{
+		CheckID:        checkID,
+		Severity:       cvssSeverity(matches[0].adv.CVSS),
+		Resource:       res,
+		Title:          title,
+		Evidence:       truncateEvidence(fmt.Sprintf("%s (advisories %s, updated %s): %s", subject, db.Source, db.Updated, list), 1024),
+		Risk:           "Известные уязвимости компонентов Kubernetes публично описаны и эксплуатируются; часть из них дает повышение привилегий или выход на узел",
+		Recommendation: fmt.Sprintf(rec, "v"+upgradeTo),
+	}
+}
+
+// describeMatches sorts matches by CVSS, highest first, and returns the evidence list
+// and the lowest version that fixes all of them.
+func describeMatches(matches []advisoryMatch) (string, string) {
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].adv.CVSS > matches[j].adv.CVSS })
+	var parts []string
+	var upgrade [3]int
+	upgradeTo := ""
+	for _, m := range matches {
+		ids := strings.Join(append([]string{m.adv.ID}, m.adv.Aliases...), "/")
+		parts = append(parts, fmt.Sprintf("%s %s cvss=%.1f fixed=%s (%s)", ids, m.component, m.adv.CVSS, m.fixed, m.adv.Summary))
+		if fv, _ := parseVersion(m.fixed); upgradeTo == "" || versionLess(upgrade, fv) {
+			upgrade, upgradeTo = fv, m.fixed
+		}
+	}
+	return strings.Join(parts, "; "), upgradeTo
+}
+
+func cvssSeverity(score float64) model.Severity {
+	switch {
+	case score >= 9:
+		return model.SeverityCritical
+	case score >= 7:
+		return model.SeverityHigh
+	case score >= 4:
+		return model.SeverityMedium
+	}
+	return model.SeverityLow
+}
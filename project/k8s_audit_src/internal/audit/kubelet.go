@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// defaultEventRecordQPS is the kubelet default; lower values drop events under load.
+const defaultEventRecordQPS = 50
+
+var weakCipherMarkers = []string{"_CBC_", "RC4", "3DES", "TLS_RSA_WITH_"}
+
+// DetectKubeletConfig checks kubelet settings read from configz, keyed by node name.
+func DetectKubeletConfig(configs map[string]*k8s.KubeletConfiguration) []model.Finding {
+	names := make([]string, 0, len(configs))
+	for n := range configs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var out []model.Finding
+	for _, node := range names {
+		kc := configs[node]
+		if kc == nil {
+			continue
+		}
+		res := model.ResourceRef{Kind: "Node", Name: node}
+		alwaysAllow := kc.Authorization.Mode == "AlwaysAllow"
+
+		if a := kc.Authentication.Anonymous.Enabled; a != nil && *a {
+			sev := model.SeverityHigh
+			ev := "authentication.anonymous.enabled=true"
+			if alwaysAllow {
+				sev = model.SeverityCritical
+				ev += " with authorization.mode=AlwaysAllow"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-001",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Анонимный доступ к kubelet API",
+				Evidence:       ev,
+				Risk:           "Запросы к порту 10250 без учетных данных обрабатываются как system:anonymous; при слабой авторизации это exec в любой Pod узла",
+				Recommendation: "Установить authentication.anonymous.enabled: false и включить webhook-аутентификацию",
+			})
+		}
+		if alwaysAllow {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-002",
+				Severity:       model.SeverityCritical,
+				Resource:       res,
+				Title:          "kubelet авторизует все запросы (AlwaysAllow)",
+				Evidence:       "authorization.mode=AlwaysAllow",
+				Risk:           "Любой аутентифицированный клиент kubelet API может выполнять команды в контейнерах и читать логи и секреты Pod'ов",
+				Recommendation: "Установить authorization.mode: Webhook",
+			})
+		}
+		if kc.ReadOnlyPort != 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-003",
+				Severity:       model.SeverityMedium,
+				Resource:       res,
+				Title:          "Открыт read-only порт kubelet",
+				Evidence:       fmt.Sprintf("readOnlyPort=%d", kc.ReadOnlyPort),
+				Risk:           "Порт без аутентификации раскрывает список Pod'ов, их спецификации и переменные окружения",
+				Recommendation: "Установить readOnlyPort: 0",
+			})
+		}
+		if !kc.ProtectKernelDefaults {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-004",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "protectKernelDefaults отключен",
+				Evidence:       "protectKernelDefaults=false",
+				Risk:           "kubelet изменяет параметры ядра узла вместо того чтобы отказаться стартовать при их несоответствии",
+				Recommendation: "Установить protectKernelDefaults: true и задать нужные sysctl на узле",
+			})
+		}
+		if !kc.SeccompDefault {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-005",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "seccompDefault отключен",
+				Evidence:       "seccompDefault=false",
+				Risk:           "Контейнеры без seccompProfile запускаются Unconfined",
+				Recommendation: "Установить seccompDefault: true (RuntimeDefault для всех контейнеров)",
+			})
+		}
+		if !kc.RotateCertificates {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-006",
+				Severity:       model.SeverityMedium,
+				Resource:       res,
+				Title:          "Ротация клиентского сертификата kubelet отключена",
+				Evidence:       "rotateCertificates=false",
+				Risk:           "Долгоживущий клиентский сертификат узла остается действительным при краже и истекает без замены",
+				Recommendation: "Установить rotateCertificates: true",
+			})
+		}
+		if !kc.ServerTLSBootstrap {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-007",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "Серверный сертификат kubelet не выпускается через CSR",
+				Evidence:       "serverTLSBootstrap=false",
+				Risk:           "kubelet использует самоподписанный серверный сертификат, и kube-apiserver не может проверить подлинность узла",
+				Recommendation: "Установить serverTLSBootstrap: true и одобрять CSR kubelet-serving",
+			})
+		}
+		if q := kc.EventRecordQPS; q != nil && *q > 0 && *q < defaultEventRecordQPS {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-008",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "Ограничена запись событий kubelet",
+				Evidence:       fmt.Sprintf("eventRecordQPS=%d (default %d)", *q, defaultEventRecordQPS),
+				Risk:           "При всплеске активности события отбрасываются, и действия атакующего не попадают в журнал",
+				Recommendation: "Установить eventRecordQPS: 0 или значение, достаточное для нагрузки узла",
+			})
+		}
+		var tlsIssues []string
+		for _, c := range kc.TLSCipherSuites {
+			for _, m := range weakCipherMarkers {
+				if strings.Contains(c, m) {
+					tlsIssues = append(tlsIssues, c)
+					break
+				}
+			}
+		}
+		if len(tlsIssues) > 0 {
+			tlsIssues = []string{"weak tlsCipherSuites: " + strings.Join(tlsIssues, ", ")}
+		}
+		if kc.TLSMinVersion == "VersionTLS10" || kc.TLSMinVersion == "VersionTLS11" {
+			tlsIssues = append(tlsIssues, "tlsMinVersion="+kc.TLSMinVersion)
+		}
+		if len(tlsIssues) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-KUBELET-009",
+				Severity:       model.SeverityMedium,
+				Resource:       res,
+				Title:          "Слабые параметры TLS kubelet",
+				Evidence:       strings.Join(tlsIssues, "; "),
+				Risk:           "Устаревшие шифры и версии TLS позволяют расшифровать или подменить трафик к kubelet API",
+				Recommendation: "Оставить только ECDHE с AES-GCM/ChaCha20 и tlsMinVersion: VersionTLS12",
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,392 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// MeshInputs holds Istio and Linkerd security objects.
+type MeshInputs struct {
+	IstioInstalled     bool
+	IstioRootNamespace string
+	// Pods (all namespaces) are used to detect a running istiod; the CRDs alone may be leftovers.
+	Pods                       []k8s.Pod
+	PeerAuthentications        []k8s.PeerAuthentication
+	IstioAuthorizationPolicies []k8s.IstioAuthorizationPolicy
+
+	LinkerdInstalled              bool
+	LinkerdServers                []k8s.LinkerdServer
+	LinkerdAuthorizationPolicies  []k8s.LinkerdAuthorizationPolicy
+	LinkerdServerAuthorizations   []k8s.LinkerdServerAuthorization
+	LinkerdNetworkAuthentications []k8s.LinkerdNetworkAuthentication
+	LinkerdMeshTLSAuthentications []k8s.LinkerdMeshTLSAuthentication
+}
+
+// Proxy admin ports: Envoy admin (bound to localhost by default) and linkerd-proxy admin.
+var meshAdminPorts = map[int32]string{
+	15000: "Envoy admin",
+	4191:  "linkerd-proxy admin",
+}
+
+const linkerdInboundPolicyAnnotation = "config.linkerd.io/default-inbound-policy"
+
+func DetectServiceMesh(namespaces []k8s.Namespace, svcs []k8s.Service, m MeshInputs) []model.Finding {
+	if !m.IstioInstalled && !m.LinkerdInstalled {
+		return nil
+	}
+	var out []model.Finding
+	if m.IstioInstalled {
+		out = append(out, detectIstio(namespaces, m)...)
+	}
+	if m.LinkerdInstalled {
+		out = append(out, detectLinkerd(namespaces, m)...)
+	}
+
+	for _, s := range svcs {
+		typ := strings.ToLower(s.Spec.Type)
+		external := typ == "nodeport" || (typ == "loadbalancer" && !isInternalLB(s.Metadata.Annotations)) || len(s.Spec.ExternalIPs) > 0
+		for _, p := range s.Spec.Ports {
+			name, ok := meshAdminPorts[p.Port]
+			if !ok && !p.TargetPort.IsString {
+				name, ok = meshAdminPorts[p.TargetPort.IntVal]
+			}
+			if !ok {
+				continue
+			}
+			sev := model.SeverityHigh
+			if external {
+				sev = model.SeverityCritical
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-005",
+				Severity:       sev,
+				Resource:       model.ResourceRef{Kind: "Service", Namespace: s.Metadata.Namespace, Name: s.Metadata.Name},
+				Title:          fmt.Sprintf("Service публикует порт %s", name),
+				Evidence:       fmt.Sprintf("type=%s port=%d targetPort=%s", s.Spec.Type, p.Port, p.TargetPort.String()),
+				Risk:           "Admin-интерфейс прокси раскрывает конфигурацию, сертификаты и позволяет менять поведение sidecar",
+				Recommendation: "Убрать порт admin-интерфейса из Service",
+			})
+		}
+	}
+	return out
+}
+
+func detectIstio(namespaces []k8s.Namespace, m MeshInputs) []model.Finding {
+	var out []model.Finding
+	root := m.IstioRootNamespace
+	if root == "" {
+		root = "istio-system"
+	}
+
+	meshStrict := false
+	for _, pa := range m.PeerAuthentications {
+		ref := model.ResourceRef{Kind: "PeerAuthentication", Namespace: pa.Metadata.Namespace, Name: pa.Metadata.Name}
+		wide := pa.Spec.Selector == nil || len(pa.Spec.Selector.MatchLabels) == 0
+		mode := ""
+		if pa.Spec.MTLS != nil {
+			mode = strings.ToUpper(pa.Spec.MTLS.Mode)
+		}
+		if wide && pa.Metadata.Namespace == root && mode == "STRICT" {
+			meshStrict = true
+		}
+		if f, ok := weakMTLSFinding(ref, "mtls.mode", mode); ok {
+			out = append(out, f)
+		}
+		for port, pm := range pa.Spec.PortLevelMTLS {
+			if f, ok := weakMTLSFinding(ref, "portLevelMtls["+port+"].mode", strings.ToUpper(pm.Mode)); ok {
+				out = append(out, f)
+			}
+		}
+	}
+	if !meshStrict {
+		out = append(out, model.Finding{
+			CheckID:        "K8S-MESH-002",
+			Severity:       model.SeverityMedium,
+			Resource:       model.ResourceRef{Kind: "Cluster", Name: "istio"},
+			Title:          "Нет mesh-wide STRICT mTLS в Istio",
+			Evidence:       fmt.Sprintf("no PeerAuthentication without selector and mtls.mode=STRICT in root namespace %q", root),
+			Risk:           "По умолчанию Istio принимает plaintext (PERMISSIVE): workload'ы доступны в обход mTLS и AuthorizationPolicy по principal",
+			Recommendation: fmt.Sprintf("Создать PeerAuthentication default в %s с mtls.mode: STRICT", root),
+		})
+	}
+
+	for _, ap := range m.IstioAuthorizationPolicies {
+		action := strings.ToUpper(ap.Spec.Action)
+		if action != "" && action != "ALLOW" {
+			continue
+		}
+		ref := model.ResourceRef{Kind: "AuthorizationPolicy", Namespace: ap.Metadata.Namespace, Name: ap.Metadata.Name}
+		for i, r := range ap.Spec.Rules {
+			anySource := len(r.From) == 0
+			for _, f := range r.From {
+				if istioSourceOpen(f.Source) {
+					anySource = true
+				}
+			}
+			switch {
+			case anySource && len(r.To) == 0 && len(r.When) == 0:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-MESH-003",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "Istio AuthorizationPolicy разрешает все",
+					Evidence:       fmt.Sprintf("action=ALLOW rules[%d] has no from/to/when restrictions", i),
+					Risk:           "Политика разрешает любой запрос и отменяет deny-by-default для выбранных workload'ов",
+					Recommendation: "Указать source.principals/namespaces и операции, которые действительно нужны",
+				})
+			case anySource:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-MESH-003",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "Istio AuthorizationPolicy без ограничения источника",
+					Evidence:       fmt.Sprintf("action=ALLOW rules[%d]: from is empty or matches any source", i),
+					Risk:           "Любой клиент (в т.ч. без mTLS-идентичности) получает доступ к разрешенным операциям",
+					Recommendation: "Добавить from.source.principals или namespaces",
+				})
+			}
+		}
+	}
+
+	controlPlane := istiodRunning(m.Pods)
+	for _, ns := range namespaces {
+		if isSystemNamespace(ns.Metadata.Name) {
+			continue
+		}
+		l := ns.Metadata.Labels
+		ref := model.ResourceRef{Kind: "Namespace", Name: ns.Metadata.Name}
+		switch {
+		case l["istio-injection"] == "disabled":
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-004",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "Istio sidecar injection отключен в namespace",
+				Evidence:       "label istio-injection=disabled",
+				Risk:           "Трафик workload'ов идет вне mesh: без mTLS и AuthorizationPolicy",
+				Recommendation: "Включить injection (istio-injection=enabled или istio.io/rev) или ambient mode",
+			})
+		case controlPlane && l["istio-injection"] != "enabled" && l["istio.io/rev"] == "" && l["istio.io/dataplane-mode"] != "ambient":
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-004",
+				Severity:       model.SeverityLow,
+				Resource:       ref,
+				Title:          "Namespace не включен в Istio mesh",
+				Evidence:       "no istio-injection, istio.io/rev or istio.io/dataplane-mode label",
+				Risk:           "Workload'ы namespace не защищены mTLS и политиками mesh",
+				Recommendation: "Включить injection или ambient mode для namespace",
+			})
+		}
+	}
+	return out
+}
+
+// istiodRunning reports whether an Istio control plane pod (istiod / pilot) is present.
+func istiodRunning(pods []k8s.Pod) bool {
+	for _, p := range pods {
+		if p.Metadata.Labels["app"] == "istiod" {
+			return true
+		}
+		for _, c := range p.Spec.Containers {
+			if repositoryMatches(imageref.Parse(c.Image).Repository, "pilot") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func weakMTLSFinding(ref model.ResourceRef, field, mode string) (model.Finding, bool) {
+	switch mode {
+	case "PERMISSIVE":
+		return model.Finding{
+			CheckID:        "K8S-MESH-001",
+			Severity:       model.SeverityMedium,
+			Resource:       ref,
+			Title:          "PeerAuthentication в режиме PERMISSIVE",
+			Evidence:       field + "=PERMISSIVE",
+			Risk:           "Workload принимает plaintext-трафик в обход mTLS",
+			Recommendation: "Перевести в STRICT после миграции клиентов",
+		}, true
+	case "DISABLE":
+		return model.Finding{
+			CheckID:        "K8S-MESH-001",
+			Severity:       model.SeverityHigh,
+			Resource:       ref,
+			Title:          "PeerAuthentication отключает mTLS",
+			Evidence:       field + "=DISABLE",
+			Risk:           "Трафик не шифруется и не аутентифицируется",
+			Recommendation: "Использовать mtls.mode: STRICT",
+		}, true
+	}
+	return model.Finding{}, false
+}
+
+func istioSourceOpen(s k8s.IstioSource) bool {
+	if hasStar(s.Principals) || hasStar(s.Namespaces) || hasStar(s.RequestPrincipals) {
+		return true
+	}
+	return len(s.Principals) == 0 && len(s.NotPrincipals) == 0 && len(s.RequestPrincipals) == 0 &&
+		len(s.NotRequestPrincipals) == 0 && len(s.Namespaces) == 0 && len(s.NotNamespaces) == 0 &&
+		len(s.IPBlocks) == 0 && len(s.NotIPBlocks) == 0 && len(s.RemoteIPBlocks) == 0 && len(s.NotRemoteIPBlocks) == 0
+}
+
+func detectLinkerd(namespaces []k8s.Namespace, m MeshInputs) []model.Finding {
+	var out []model.Finding
+
+	for _, ns := range namespaces {
+		if isSystemNamespace(ns.Metadata.Name) {
+			continue
+		}
+		ann := ns.Metadata.Annotations
+		ref := model.ResourceRef{Kind: "Namespace", Name: ns.Metadata.Name}
+		policy := ann[linkerdInboundPolicyAnnotation]
+		switch {
+		case ann["linkerd.io/inject"] == "disabled":
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-004",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "Linkerd proxy injection отключен в namespace",
+				Evidence:       "annotation linkerd.io/inject=disabled",
+				Risk:           "Трафик workload'ов идет вне mesh: без mTLS и политик авторизации",
+				Recommendation: "Установить linkerd.io/inject: enabled",
+			})
+		case strings.HasSuffix(policy, "-unauthenticated"):
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-001",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "Linkerd default-inbound-policy допускает неаутентифицированный трафик",
+				Evidence:       fmt.Sprintf("annotation %s=%s", linkerdInboundPolicyAnnotation, policy),
+				Risk:           "Pod'ы принимают соединения без mTLS-идентичности",
+				Recommendation: "Использовать all-authenticated/cluster-authenticated или deny с явными AuthorizationPolicy",
+			})
+		case ann["linkerd.io/inject"] == "enabled" && policy == "":
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-006",
+				Severity:       model.SeverityLow,
+				Resource:       ref,
+				Title:          "Linkerd default-inbound-policy не задан для namespace",
+				Evidence:       fmt.Sprintf("annotation %s absent (cluster default applies, all-unauthenticated unless changed at install)", linkerdInboundPolicyAnnotation),
+				Risk:           "При стандартной установке mesh принимает неаутентифицированный трафик",
+				Recommendation: "Задать cluster-authenticated/all-authenticated на namespace или при установке Linkerd",
+			})
+		}
+	}
+
+	for _, s := range m.LinkerdServers {
+		if strings.HasSuffix(s.Spec.AccessPolicy, "-unauthenticated") {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-001",
+				Severity:       model.SeverityMedium,
+				Resource:       model.ResourceRef{Kind: "Server", Namespace: s.Metadata.Namespace, Name: s.Metadata.Name},
+				Title:          "Linkerd Server допускает неаутентифицированный трафик",
+				Evidence:       fmt.Sprintf("port=%s accessPolicy=%s", s.Spec.Port.String(), s.Spec.AccessPolicy),
+				Risk:           "Порт принимает соединения без mTLS-идентичности",
+				Recommendation: "Установить accessPolicy: deny и разрешать клиентов через AuthorizationPolicy",
+			})
+		}
+	}
+
+	netAuth := map[string]k8s.LinkerdNetworkAuthentication{}
+	for _, n := range m.LinkerdNetworkAuthentications {
+		netAuth[n.Metadata.Namespace+"/"+n.Metadata.Name] = n
+	}
+	meshAuth := map[string]k8s.LinkerdMeshTLSAuthentication{}
+	for _, a := range m.LinkerdMeshTLSAuthentications {
+		meshAuth[a.Metadata.Namespace+"/"+a.Metadata.Name] = a
+	}
+	for _, ap := range m.LinkerdAuthorizationPolicies {
+		ref := model.ResourceRef{Kind: "AuthorizationPolicy", Namespace: ap.Metadata.Namespace, Name: ap.Metadata.Name}
+		target := fmt.Sprintf("%s/%s", ap.Spec.TargetRef.Kind, ap.Spec.TargetRef.Name)
+		if len(ap.Spec.RequiredAuthenticationRefs) == 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-003",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Linkerd AuthorizationPolicy разрешает все",
+				Evidence:       fmt.Sprintf("targetRef=%s requiredAuthenticationRefs=[]", target),
+				Risk:           "Любой клиент, включая неаутентифицированные, получает доступ к цели",
+				Recommendation: "Указать MeshTLSAuthentication с конкретными identities",
+			})
+			continue
+		}
+		for _, r := range ap.Spec.RequiredAuthenticationRefs {
+			ns := r.Namespace
+			if ns == "" {
+				ns = ap.Metadata.Namespace
+			}
+			switch r.Kind {
+			case "NetworkAuthentication":
+				n, ok := netAuth[ns+"/"+r.Name]
+				if !ok {
+					continue
+				}
+				for _, nw := range n.Spec.Networks {
+					if hasOpenCIDR([]string{nw.CIDR}) {
+						out = append(out, model.Finding{
+							CheckID:        "K8S-MESH-003",
+							Severity:       model.SeverityHigh,
+							Resource:       ref,
+							Title:          "Linkerd AuthorizationPolicy разрешает любой сетевой источник",
+							Evidence:       fmt.Sprintf("targetRef=%s -> NetworkAuthentication %q cidr=%s", target, r.Name, nw.CIDR),
+							Risk:           "Доступ разрешен с любого IP без mTLS",
+							Recommendation: "Сузить networks или использовать MeshTLSAuthentication",
+						})
+					}
+				}
+			case "MeshTLSAuthentication":
+				a, ok := meshAuth[ns+"/"+r.Name]
+				if ok && hasStar(a.Spec.Identities) {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-MESH-003",
+						Severity:       model.SeverityMedium,
+						Resource:       ref,
+						Title:          "Linkerd AuthorizationPolicy разрешает любую mesh-идентичность",
+						Evidence:       fmt.Sprintf("targetRef=%s -> MeshTLSAuthentication %q identities=[*]", target, r.Name),
+						Risk:           "Любой meshed workload кластера получает доступ",
+						Recommendation: "Перечислить конкретные ServiceAccount-идентичности",
+					})
+				}
+			}
+		}
+	}
+
+	for _, sa := range m.LinkerdServerAuthorizations {
+		ref := model.ResourceRef{Kind: "ServerAuthorization", Namespace: sa.Metadata.Namespace, Name: sa.Metadata.Name}
+		cl := sa.Spec.Client
+		open := cl.Unauthenticated
+		for _, nw := range cl.Networks {
+			if hasOpenCIDR([]string{nw.CIDR}) {
+				open = true
+			}
+		}
+		if open {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-003",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Linkerd ServerAuthorization допускает неаутентифицированных клиентов",
+				Evidence:       fmt.Sprintf("server=%q client.unauthenticated=%v networks=%d", sa.Spec.Server.Name, cl.Unauthenticated, len(cl.Networks)),
+				Risk:           "Доступ к серверу возможен без mTLS-идентичности",
+				Recommendation: "Использовать client.meshTLS с конкретными serviceAccounts",
+			})
+		} else if cl.MeshTLS != nil && (cl.MeshTLS.UnauthenticatedTLS || hasStar(cl.MeshTLS.Identities)) {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-MESH-003",
+				Severity:       model.SeverityMedium,
+				Resource:       ref,
+				Title:          "Linkerd ServerAuthorization разрешает любую mesh-идентичность",
+				Evidence:       fmt.Sprintf("server=%q meshTLS.identities=%v unauthenticatedTLS=%v", sa.Spec.Server.Name, cl.MeshTLS.Identities, cl.MeshTLS.UnauthenticatedTLS),
+				Risk:           "Любой meshed workload кластера получает доступ",
+				Recommendation: "Перечислить конкретные ServiceAccount-идентичности",
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,317 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Label selector evaluation.
+
+func selectorEmpty(sel k8s.LabelSelector) bool {
+	return len(sel.MatchLabels) == 0 && len(sel.MatchExpressions) == 0
+}
+
+func selectorMatches(sel k8s.LabelSelector, labels map[string]string) bool {
+	if !labelsMatch(sel.MatchLabels, labels) {
+		return false
+	}
+	for _, r := range sel.MatchExpressions {
+		v, ok := labels[r.Key]
+		switch r.Operator {
+		case "In":
+			if !ok || !containsAny(r.Values, v) {
+				return false
+			}
+		case "NotIn":
+			if ok && containsAny(r.Values, v) {
+				return false
+			}
+		case "Exists":
+			if !ok {
+				return false
+			}
+		case "DoesNotExist":
+			if ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func selectPodsBySelector(pods []k8s.Pod, sel k8s.LabelSelector) []k8s.Pod {
+	var out []k8s.Pod
+	for _, p := range pods {
+		if selectorMatches(sel, p.Metadata.Labels) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// policyTypes applies the API defaulting: Ingress always, Egress if egress rules exist.
+func policyTypes(np k8s.NetworkPolicy) []string {
+	if len(np.Spec.PolicyTypes) > 0 {
+		return uniqStrings(np.Spec.PolicyTypes)
+	}
+	pt := []string{"Ingress"}
+	if len(np.Spec.Egress) > 0 {
+		pt = append(pt, "Egress")
+	}
+	return pt
+}
+
+func allowsAllIngress(np k8s.NetworkPolicy) bool {
+	for _, r := range np.Spec.Ingress {
+		if len(r.From) == 0 && len(r.Ports) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func allowsAllEgress(np k8s.NetworkPolicy) bool {
+	for _, r := range np.Spec.Egress {
+		if len(r.To) == 0 && len(r.Ports) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func detectIneffectivePolicies(in NetworkInputs) []model.Finding {
+	var out []model.Finding
+
+	allNS := in.AllNamespaces
+	if len(allNS) == 0 {
+		allNS = in.Namespaces
+	}
+	inScope := map[string]struct{}{}
+	for _, ns := range in.Namespaces {
+		inScope[ns.Metadata.Name] = struct{}{}
+	}
+	var podsByNS map[string][]k8s.Pod
+	if in.PodsListed {
+		podsByNS = map[string][]k8s.Pod{}
+		for _, p := range in.Pods {
+			podsByNS[p.Metadata.Namespace] = append(podsByNS[p.Metadata.Namespace], p)
+		}
+	}
+	byNS := map[string][]k8s.NetworkPolicy{}
+	for _, np := range in.NetworkPolicies {
+		if _, ok := inScope[np.Metadata.Namespace]; ok {
+			byNS[np.Metadata.Namespace] = append(byNS[np.Metadata.Namespace], np)
+		}
+	}
+
+	for ns, pols := range byNS {
+		pods := podsByNS[ns]
+		selected := map[string]map[string]struct{}{}
+		for _, np := range pols {
+			set := map[string]struct{}{}
+			for _, p := range selectPodsBySelector(pods, np.Spec.PodSelector) {
+				set[p.Metadata.Name] = struct{}{}
+			}
+			selected[np.Metadata.Name] = set
+		}
+
+		seenSpec := map[string]string{}
+		for _, np := range pols {
+			ref := model.ResourceRef{Kind: "NetworkPolicy", Namespace: ns, Name: np.Metadata.Name}
+
+			switch {
+			case !in.PodsListed:
+				// pods unknown: cannot tell an empty selection from a failed list
+			case len(pods) == 0:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-008",
+					Severity:       model.SeverityLow,
+					Resource:       ref,
+					Title:          "NetworkPolicy в namespace без Pod'ов",
+					Evidence:       "namespace has no pods",
+					Risk:           "Политика ничего не защищает; возможно, она была создана не в том namespace",
+					Recommendation: "Проверить namespace политики или удалить ее",
+				})
+			case len(selected[np.Metadata.Name]) == 0:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-007",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "podSelector NetworkPolicy не выбирает ни одного Pod'а",
+					Evidence:       fmt.Sprintf("podSelector=%s matches 0 of %d pods", selectorString(np.Spec.PodSelector), len(pods)),
+					Risk:           "Команда считает workload сегментированным, но политика к нему не применяется",
+					Recommendation: "Исправить метки в podSelector, чтобы они совпадали с метками Pod'ов",
+				})
+			}
+
+			for i, r := range np.Spec.Ingress {
+				for _, peer := range r.From {
+					if ev := deadPeer(peer, ns, allNS, podsByNS); ev != "" {
+						out = append(out, deadPeerFinding(ref, fmt.Sprintf("ingress[%d].from: %s", i, ev)))
+					}
+				}
+			}
+			for i, r := range np.Spec.Egress {
+				for _, peer := range r.To {
+					if ev := deadPeer(peer, ns, allNS, podsByNS); ev != "" {
+						out = append(out, deadPeerFinding(ref, fmt.Sprintf("egress[%d].to: %s", i, ev)))
+					}
+				}
+			}
+
+			pt := policyTypes(np)
+			if containsAny(pt, "Egress") && len(np.Spec.Egress) == 0 {
+				// an empty podSelector without ingress rules is the usual namespace default-deny
+				if selectorEmpty(np.Spec.PodSelector) {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-NET-011",
+						Severity:       model.SeverityLow,
+						Resource:       ref,
+						Title:          "Default-deny egress для всего namespace",
+						Evidence:       fmt.Sprintf("podSelector={} policyTypes=%v, ingress rules=%d, egress section absent", pt, len(np.Spec.Ingress)),
+						Risk:           "Весь исходящий трафик namespace, включая DNS, запрещен, пока другие политики его явно не разрешат",
+						Recommendation: "Убедиться, что DNS и нужные направления разрешены отдельными egress-политиками",
+					})
+				} else {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-NET-011",
+						Severity:       model.SeverityMedium,
+						Resource:       ref,
+						Title:          "policyTypes содержит Egress без правил egress",
+						Evidence:       fmt.Sprintf("podSelector=%s policyTypes=%v, ingress rules=%d, egress section absent", selectorString(np.Spec.PodSelector), pt, len(np.Spec.Ingress)),
+						Risk:           "Весь исходящий трафик выбранных Pod'ов молча запрещен; обычно это ошибка, которую «чинят» allow-all политикой",
+						Recommendation: "Добавить явные egress-правила или убрать Egress из policyTypes",
+					})
+				}
+			}
+			if len(np.Spec.PolicyTypes) > 0 && containsAny(pt, "Ingress") && len(np.Spec.Ingress) == 0 && len(np.Spec.Egress) > 0 {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-011",
+					Severity:       model.SeverityLow,
+					Resource:       ref,
+					Title:          "policyTypes содержит Ingress без правил ingress",
+					Evidence:       fmt.Sprintf("policyTypes=%v, egress rules=%d, ingress section absent", pt, len(np.Spec.Egress)),
+					Risk:           "Весь входящий трафик выбранных Pod'ов молча запрещен; обычно это ошибка, которую «чинят» allow-all политикой",
+					Recommendation: "Добавить явные ingress-правила или убрать Ingress из policyTypes",
+				})
+			}
+
+			b, _ := json.Marshal(np.Spec)
+			if prev, ok := seenSpec[string(b)]; ok {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-010",
+					Severity:       model.SeverityLow,
+					Resource:       ref,
+					Title:          "Дублирующая NetworkPolicy",
+					Evidence:       fmt.Sprintf("spec identical to networkpolicy %q", prev),
+					Risk:           "Дубликаты усложняют ревью и маскируют реальные правила",
+					Recommendation: "Удалить дубликат",
+				})
+			} else {
+				seenSpec[string(b)] = np.Metadata.Name
+			}
+		}
+
+		// An allow-all rule makes every other rule of the same direction on those pods moot.
+		for _, a := range pols {
+			aSet := selected[a.Metadata.Name]
+			if len(aSet) == 0 {
+				continue
+			}
+			for _, dir := range []string{"Ingress", "Egress"} {
+				if (dir == "Ingress" && !allowsAllIngress(a)) || (dir == "Egress" && !allowsAllEgress(a)) {
+					continue
+				}
+				for _, b := range pols {
+					bSet := selected[b.Metadata.Name]
+					if b.Metadata.Name == a.Metadata.Name || len(bSet) == 0 || !containsAny(policyTypes(b), dir) || !subsetOf(bSet, aSet) {
+						continue
+					}
+					if (dir == "Ingress" && allowsAllIngress(b)) || (dir == "Egress" && allowsAllEgress(b)) {
+						continue
+					}
+					out = append(out, model.Finding{
+						CheckID:        "K8S-NET-010",
+						Severity:       model.SeverityMedium,
+						Resource:       model.ResourceRef{Kind: "NetworkPolicy", Namespace: ns, Name: b.Metadata.Name},
+						Title:          fmt.Sprintf("%s-правила NetworkPolicy перекрыты allow-all политикой", dir),
+						Evidence:       fmt.Sprintf("networkpolicy %q allows all %s for the same %d pod(s)", a.Metadata.Name, strings.ToLower(dir), len(bSet)),
+						Risk:           "Ограничения этой политики не действуют: правила NetworkPolicy объединяются, и allow-all разрешает все",
+						Recommendation: fmt.Sprintf("Сузить или удалить allow-all правило в %q", a.Metadata.Name),
+					})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// deadPeer describes a peer whose selectors match nothing, or returns "".
+// podsByNS is nil when pods were not listed; pod selectors are then not checked.
+func deadPeer(peer k8s.NetworkPolicyPeer, policyNS string, allNS []k8s.Namespace, podsByNS map[string][]k8s.Pod) string {
+	if peer.IPBlock != nil {
+		return ""
+	}
+	namespaces := []string{policyNS}
+	if peer.NamespaceSelector != nil {
+		namespaces = nil
+		for _, ns := range allNS {
+			if selectorMatches(*peer.NamespaceSelector, ns.Metadata.Labels) {
+				namespaces = append(namespaces, ns.Metadata.Name)
+			}
+		}
+		if len(namespaces) == 0 {
+			return fmt.Sprintf("namespaceSelector=%s matches no namespace", selectorString(*peer.NamespaceSelector))
+		}
+	}
+	if podsByNS == nil || peer.PodSelector == nil || selectorEmpty(*peer.PodSelector) {
+		return ""
+	}
+	for _, ns := range namespaces {
+		if len(selectPodsBySelector(podsByNS[ns], *peer.PodSelector)) > 0 {
+			return ""
+		}
+	}
+	return fmt.Sprintf("podSelector=%s matches no pod in %v", selectorString(*peer.PodSelector), namespaces)
+}
+
+func deadPeerFinding(ref model.ResourceRef, ev string) model.Finding {
+	return model.Finding{
+		CheckID:        "K8S-NET-009",
+		Severity:       model.SeverityLow,
+		Resource:       ref,
+		Title:          "Правило NetworkPolicy ссылается на несуществующие namespace/метки",
+		Evidence:       ev,
+		Risk:           "Правило не совпадает ни с чем; нужный трафик заблокирован или разрешение было задумано для другого источника",
+		Recommendation: "Проверить метки namespace (kubernetes.io/metadata.name) и Pod'ов в селекторах",
+	}
+}
+
+func selectorString(sel k8s.LabelSelector) string {
+	if selectorEmpty(sel) {
+		return "{}"
+	}
+	parts := []string{}
+	for k, v := range sel.MatchLabels {
+		parts = append(parts, k+"="+v)
+	}
+	for _, r := range sel.MatchExpressions {
+		parts = append(parts, fmt.Sprintf("%s %s %v", r.Key, r.Operator, r.Values))
+	}
+	return "{" + strings.Join(uniqStrings(parts), ",") + "}"
+}
+
+func subsetOf(a, b map[string]struct{}) bool {
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"testing"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+func TestEgressWithoutRules(t *testing.T) {
+	app := k8s.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	allowAll := []k8s.NetworkPolicyIngressRule{{}}
+	tests := []struct {
+		name string
+		spec k8s.NetworkPolicySpec
+		want model.Severity // "" for no K8S-NET-011
+	}{
+		{"egress only, selected pods", k8s.NetworkPolicySpec{PodSelector: app, PolicyTypes: []string{"Egress"}}, model.SeverityMedium},
+		{"egress only, whole namespace", k8s.NetworkPolicySpec{PolicyTypes: []string{"Egress"}}, model.SeverityLow},
+		{"default deny both", k8s.NetworkPolicySpec{PolicyTypes: []string{"Ingress", "Egress"}}, model.SeverityLow},
+		{"ingress rules, no egress rules", k8s.NetworkPolicySpec{PodSelector: app, PolicyTypes: []string{"Ingress", "Egress"}, Ingress: allowAll}, model.SeverityMedium},
+		{"egress rules present", k8s.NetworkPolicySpec{PodSelector: app, PolicyTypes: []string{"Egress"}, Egress: []k8s.NetworkPolicyEgressRule{{}}}, ""},
+		{"ingress only by default", k8s.NetworkPolicySpec{PodSelector: app, Ingress: allowAll}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := NetworkInputs{
+				Namespaces: []k8s.Namespace{{Metadata: k8s.ObjectMeta{Name: "shop"}}},
+				Pods:       []k8s.Pod{{Metadata: k8s.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web"}}}},
+				PodsListed: true,
+				NetworkPolicies: []k8s.NetworkPolicy{
+					{Metadata: k8s.ObjectMeta{Name: "np", Namespace: "shop"}, Spec: tt.spec},
+				},
+			}
+			var got []model.Finding
+			for _, f := range detectIneffectivePolicies(in) {
+				if f.CheckID == "K8S-NET-011" {
+					got = append(got, f)
+				}
+			}
+			switch {
+			case tt.want == "" && len(got) > 0:
+				t.Errorf("unexpected finding: %+v", got)
+			case tt.want != "" && (len(got) != 1 || got[0].Severity != tt.want):
+				t.Errorf("got %+v, want one %s finding", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,61 +2,88 @@ package audit
 
 import (
 	"fmt"
-	"strings"
 
 	"example.com/k8s-audit/internal/k8s"
 	"example.com/k8s-audit/internal/model"
 )
 
-func DetectNetwork(namespaces []k8s.Namespace, nps []k8s.NetworkPolicy, svcs []k8s.Service, ing []k8s.Ingress) []model.Finding {
+// NetworkInputs is the cluster state DetectNetwork works on.
+type NetworkInputs struct {
+	Namespaces    []k8s.Namespace // namespaces in scope
+	AllNamespaces []k8s.Namespace // every namespace, for namespaceSelector resolution
+	Pods          []k8s.Pod       // every pod, for podSelector resolution
+	// PodsListed is false when pods could not be listed; podSelector checks are skipped.
+	PodsListed      bool
+	NetworkPolicies []k8s.NetworkPolicy
+	Services        []k8s.Service
+	Ingresses       []k8s.Ingress
+	Gateway         GatewayAPI
+	CNI             CNIPolicies
+}
+
+func DetectNetwork(in NetworkInputs) []model.Finding {
 	var out []model.Finding
 	byNS := map[string][]k8s.NetworkPolicy{}
-	for _, np := range nps {
+	for _, np := range in.NetworkPolicies {
 		byNS[np.Metadata.Namespace] = append(byNS[np.Metadata.Namespace], np)
 	}
+	podsByNS := map[string][]k8s.Pod{}
+	for _, p := range in.Pods {
+		podsByNS[p.Metadata.Namespace] = append(podsByNS[p.Metadata.Namespace], p)
+	}
 
-	for _, ns := range namespaces {
+	for _, ns := range in.Namespaces {
 		n := ns.Metadata.Name
 		pols := byNS[n]
-		if len(pols) == 0 {
+		cni := cniSegmentation(ns, in.CNI)
+		if len(pols) == 0 && len(cni.Policies) == 0 {
 			out = append(out, model.Finding{
 				CheckID:        "K8S-NET-001",
 				Severity:       model.SeverityHigh,
 				Resource:       model.ResourceRef{Kind: "Namespace", Name: n},
 				Title:          "В namespace нет NetworkPolicy (по умолчанию allow-all)",
-				Evidence:       "networkpolicies=0",
+				Evidence:       "networkpolicies=0, cilium/calico policies=0",
 				Risk:           "Отсутствие сегментации упрощает боковое перемещение",
 				Recommendation: "Добавить default-deny ingress/egress и разрешать только нужный трафик",
 			})
 			continue
 		}
 
-		denyIngress := false
-		denyEgress := false
-		for _, np := range pols {
-			selectsAll := len(np.Spec.PodSelector.MatchLabels) == 0
-			if !selectsAll {
-				continue
-			}
-			pt := uniqStrings(np.Spec.PolicyTypes)
-			if len(pt) == 0 {
-				if np.Spec.Ingress != nil {
-					pt = append(pt, "Ingress")
-				}
-				if np.Spec.Egress != nil {
-					pt = append(pt, "Egress")
+		// Policies that select no running pod do not segment anything.
+		effective := pols
+		if pods := podsByNS[n]; len(pods) > 0 {
+			effective = nil
+			for _, np := range pols {
+				if len(selectPodsBySelector(pods, np.Spec.PodSelector)) > 0 {
+					effective = append(effective, np)
 				}
-				pt = uniqStrings(pt)
 			}
-			if containsAny(pt, "Ingress") {
-				if np.Spec.Ingress != nil && len(np.Spec.Ingress) == 0 {
-					denyIngress = true
-				}
+		}
+		if len(pols) > 0 && len(effective) == 0 && len(cni.Policies) == 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NET-001",
+				Severity:       model.SeverityHigh,
+				Resource:       model.ResourceRef{Kind: "Namespace", Name: n},
+				Title:          "NetworkPolicy в namespace не выбирают ни одного Pod'а (фактически allow-all)",
+				Evidence:       fmt.Sprintf("networkpolicies=%d, selecting existing pods=0", len(pols)),
+				Risk:           "Политики существуют, но не применяются: сегментации нет",
+				Recommendation: "Исправить podSelector'ы и добавить default-deny ingress/egress",
+			})
+			continue
+		}
+
+		denyIngress := len(cni.DenyIngress) > 0
+		denyEgress := len(cni.DenyEgress) > 0
+		for _, np := range effective {
+			if !selectorEmpty(np.Spec.PodSelector) {
+				continue
 			}
-			if containsAny(pt, "Egress") {
-				if np.Spec.Egress != nil && len(np.Spec.Egress) == 0 {
-					denyEgress = true
-				}
+			pt := policyTypes(np)
+			if containsAny(pt, "Ingress") && len(np.Spec.Ingress) == 0 {
+				denyIngress = true
+			}
+			if containsAny(pt, "Egress") && len(np.Spec.Egress) == 0 {
+				denyEgress = true
 			}
 		}
 		if !denyIngress {
@@ -65,7 +92,7 @@ func DetectNetwork(namespaces []k8s.Namespace, nps []k8s.NetworkPolicy, svcs []k
 				Severity:       model.SeverityMedium,
 				Resource:       model.ResourceRef{Kind: "Namespace", Name: n},
 				Title:          "Нет default-deny Ingress для всех Pod'ов",
-				Evidence:       "не найден NetworkPolicy с podSelector:{} и ingress:[]",
+				Evidence:       "не найден NetworkPolicy с podSelector:{} и policyTypes:[Ingress] без ingress-правил (и аналога в Cilium/Calico)",
 				Risk:           "Входящий трафик к Pod'ам может быть открыт шире, чем требуется",
 				Recommendation: "Добавить default-deny ingress policy (podSelector: {})",
 			})
@@ -76,53 +103,17 @@ func DetectNetwork(namespaces []k8s.Namespace, nps []k8s.NetworkPolicy, svcs []k
 				Severity:       model.SeverityMedium,
 				Resource:       model.ResourceRef{Kind: "Namespace", Name: n},
 				Title:          "Нет default-deny Egress для всех Pod'ов",
-				Evidence:       "не найден NetworkPolicy с podSelector:{} и egress:[]",
+				Evidence:       "не найден NetworkPolicy с podSelector:{} и policyTypes:[Egress] без egress-правил (и аналога в Cilium/Calico)",
 				Risk:           "Исходящий трафик может позволить утечки и доступ к внешним сервисам",
 				Recommendation: "Добавить default-deny egress и разрешить только нужные направления",
 			})
 		}
 	}
 
-	for _, s := range svcs {
-		if strings.EqualFold(s.Spec.Type, "NodePort") {
-			ports := []string{}
-			for _, p := range s.Spec.Ports {
-				ports = append(ports, fmt.Sprintf("%d->%d/%s", p.Port, p.NodePort, strings.ToUpper(p.Protocol)))
-			}
-			out = append(out, model.Finding{
-				CheckID:        "K8S-NET-004",
-				Severity:       model.SeverityHigh,
-				Resource:       model.ResourceRef{Kind: "Service", Namespace: s.Metadata.Namespace, Name: s.Metadata.Name},
-				Title:          "Service типа NodePort",
-				Evidence:       fmt.Sprintf("type=NodePort ports=%v", ports),
-				Risk:           "NodePort расширяет поверхность атаки (порт на узлах)",
-				Recommendation: "Избегать NodePort, использовать Ingress/LoadBalancer с явным контролем доступа",
-			})
-		}
-		if strings.EqualFold(s.Spec.Type, "LoadBalancer") {
-			out = append(out, model.Finding{
-				CheckID:        "K8S-NET-006",
-				Severity:       model.SeverityMedium,
-				Resource:       model.ResourceRef{Kind: "Service", Namespace: s.Metadata.Namespace, Name: s.Metadata.Name},
-				Title:          "Service типа LoadBalancer",
-				Evidence:       "type=LoadBalancer",
-				Risk:           "Возможна внешняя экспозиция сервиса",
-				Recommendation: "Проверить необходимость внешнего доступа и ограничить источники, настроить TLS",
-			})
-		}
-	}
-
-	for _, ig := range ing {
-		out = append(out, model.Finding{
-			CheckID:        "K8S-NET-005",
-			Severity:       model.SeverityMedium,
-			Resource:       model.ResourceRef{Kind: "Ingress", Namespace: ig.Metadata.Namespace, Name: ig.Metadata.Name},
-			Title:          "Ingress объект присутствует",
-			Evidence:       "networking.k8s.io/v1 Ingress",
-			Risk:           "Ingress открывает входящий трафик",
-			Recommendation: "Проверить TLS, auth, whitelist и корректность правил",
-		})
-	}
+	out = append(out, detectIneffectivePolicies(in)...)
+	out = append(out, detectServices(in.Services)...)
+	out = append(out, detectIngresses(in.Ingresses)...)
+	out = append(out, detectGatewayAPI(in.Gateway)...)
 
 	return out
 }
@@ -0,0 +1,270 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Node posture from the Node API. Missing control-plane taints are K8S-SCHED-002.
+
+// maxKubeletSkew is the number of minor versions a kubelet may lag behind kube-apiserver.
+const maxKubeletSkew = 3
+
+type osEOL struct {
+	image string // osImage prefix, followed by end of string, space or dot
+	eol   string
+}
+
+var osEOLs = []osEOL{
+	{"Ubuntu 16.04", "2021-04-30"},
+	{"Ubuntu 18.04", "2023-05-31"},
+	{"Ubuntu 20.04", "2025-05-31"},
+	{"Ubuntu 22.04", "2027-06-01"},
+	{"Debian GNU/Linux 9", "2022-06-30"},
+	{"Debian GNU/Linux 10", "2024-06-30"},
+	{"Debian GNU/Linux 11", "2026-08-31"},
+	{"CentOS Linux 7", "2024-06-30"},
+	{"CentOS Linux 8", "2021-12-31"},
+	{"CentOS Stream 8", "2024-05-31"},
+	{"Red Hat Enterprise Linux Server 7", "2024-06-30"},
+	{"Red Hat Enterprise Linux 7", "2024-06-30"},
+	{"Amazon Linux 2", "2026-06-30"},
+}
+
+type runtimeAdvisory struct {
+	runtime string
+	branch  string // major.minor the fix applies to; "" for every version below fixed
+	fixed   string
+	note    string
+}
+
+var runtimeAdvisories = []runtimeAdvisory{
+	{"containerd", "", "1.6.0", "containerd < 1.6 is end-of-life (CVE-2022-23648 host file read, CVE-2023-25173)"},
+	{"containerd", "1.6", "1.6.18", "CVE-2023-25173 supplementary groups bypass, CVE-2023-25153"},
+	{"containerd", "1.6", "1.6.28", "bundled runc < 1.1.12: CVE-2024-21626 container escape"},
+	{"containerd", "1.7", "1.7.13", "bundled runc < 1.1.12: CVE-2024-21626 container escape"},
+	{"cri-o", "", "1.19.0", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"cri-o", "1.19", "1.19.6", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"cri-o", "1.20", "1.20.7", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"cri-o", "1.21", "1.21.6", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"cri-o", "1.22", "1.22.3", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"cri-o", "1.23", "1.23.2", "CVE-2022-0811 (cr8escape) kernel parameter injection"},
+	{"docker", "", "20.10.9", "CVE-2021-41091 data-root permissions, CVE-2021-41089"},
+	{"docker", "24.0", "24.0.9", "bundled runc < 1.1.12: CVE-2024-21626 container escape"},
+	{"docker", "25.0", "25.0.2", "bundled runc < 1.1.12: CVE-2024-21626 container escape"},
+}
+
+// DetectNodePosture reports version skew, end-of-life OS images, vulnerable runtimes,
+// public addresses and unhealthy conditions. serverVersion is the API server gitVersion.
+func DetectNodePosture(nodes []k8s.Node, serverVersion string) []model.Finding {
+	var out []model.Finding
+	now := time.Now()
+	server, serverOK := parseVersion(serverVersion)
+	for _, n := range nodes {
+		info := n.Status.NodeInfo
+		res := model.ResourceRef{Kind: "Node", Name: n.Metadata.Name}
+		cp := isControlPlaneNode(n)
+
+		if kubelet, ok := parseVersion(info.KubeletVersion); ok && serverOK && kubelet[0] == server[0] {
+			skew := server[1] - kubelet[1]
+			var sev model.Severity
+			ev := fmt.Sprintf("kubelet %s, kube-apiserver %s", info.KubeletVersion, serverVersion)
+			switch {
+			case skew < 0:
+				sev = model.SeverityMedium
+				ev += " (kubelet newer than the API server is unsupported)"
+			case skew > maxKubeletSkew:
+				sev = model.SeverityHigh
+				ev += fmt.Sprintf(" (%d minor versions behind, beyond the supported skew of %d)", skew, maxKubeletSkew)
+			case skew >= 2:
+				sev = model.SeverityMedium
+				ev += fmt.Sprintf(" (%d minor versions behind)", skew)
+			}
+			if sev != "" {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NODE-001",
+					Severity:       sev,
+					Resource:       res,
+					Title:          "Устаревший kubelet (version skew)",
+					Evidence:       ev,
+					Risk:           "Старый kubelet не получает исправлений безопасности и может не поддерживать новые механизмы защиты API",
+					Recommendation: "Обновить узлы до версии kube-apiserver или не более чем на 1 minor ниже",
+				})
+			}
+		}
+
+		for _, e := range osEOLs {
+			if !osImageMatches(info.OSImage, e.image) {
+				continue
+			}
+			eol, _ := time.Parse("2006-01-02", e.eol)
+			if now.Before(eol) {
+				break
+			}
+			sev := model.SeverityMedium
+			if now.Sub(eol) > 2*365*24*time.Hour {
+				sev = model.SeverityHigh
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NODE-002",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "ОС узла вне срока поддержки (EOL)",
+				Evidence:       fmt.Sprintf("osImage=%q (end of life %s), kernel %s", info.OSImage, e.eol, info.KernelVersion),
+				Risk:           "Для ОС без поддержки не выходят исправления ядра и системных пакетов, включая уязвимости выхода из контейнера",
+				Recommendation: "Перевести узлы на поддерживаемый образ ОС",
+			})
+			break
+		}
+
+		if notes := runtimeIssues(info.ContainerRuntimeVersion); len(notes) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NODE-004",
+				Severity:       model.SeverityHigh,
+				Resource:       res,
+				Title:          "Уязвимая версия container runtime",
+				Evidence:       fmt.Sprintf("containerRuntimeVersion=%s: %s", info.ContainerRuntimeVersion, strings.Join(notes, "; ")),
+				Risk:           "Известные уязвимости runtime позволяют выйти из контейнера на узел",
+				Recommendation: "Обновить container runtime (и runc) до исправленной версии",
+			})
+		}
+
+		var public []string
+		for _, a := range n.Status.Addresses {
+			if a.Type != "ExternalIP" && a.Type != "InternalIP" {
+				continue
+			}
+			if ip := net.ParseIP(a.Address); ip != nil && isPublicIP(ip) {
+				public = append(public, a.Type+" "+a.Address)
+			}
+		}
+		if len(public) > 0 {
+			sev := model.SeverityMedium
+			role := "worker"
+			if cp {
+				sev, role = model.SeverityHigh, "control-plane"
+			}
+			ev := fmt.Sprintf("%s node has public addresses: %s", role, strings.Join(public, ", "))
+			for _, cidr := range nodePodCIDRs(n) {
+				if _, ipnet, err := net.ParseCIDR(cidr); err == nil && isPublicIP(ipnet.IP) {
+					ev += fmt.Sprintf("; podCIDR %s is in public address space", cidr)
+				}
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NODE-003",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Узел имеет публичный IP-адрес",
+				Evidence:       ev,
+				Risk:           "kubelet (10250), NodePort и hostPort доступны из интернета, если их не закрывает firewall",
+				Recommendation: "Размещать узлы в приватных подсетях и публиковать сервисы через балансировщик",
+			})
+		}
+
+		var bad []string
+		for _, c := range n.Status.Conditions {
+			switch {
+			case c.Type == "Ready" && c.Status != "True":
+				bad = append(bad, fmt.Sprintf("Ready=%s (%s)", c.Status, c.Reason))
+			case c.Type != "Ready" && strings.HasSuffix(c.Type, "Pressure") && c.Status == "True":
+				bad = append(bad, c.Type+"=True")
+			}
+		}
+		if len(bad) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NODE-005",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "Узел не готов или испытывает нехватку ресурсов",
+				Evidence:       strings.Join(bad, ", "),
+				Risk:           "Нехватка памяти, диска или PID на узле приводит к вытеснению Pod'ов и может быть признаком DoS",
+				Recommendation: "Проверить нагрузку на узле, requests/limits Pod'ов и состояние kubelet",
+			})
+		}
+	}
+	return out
+}
+
+// parseVersion extracts major, minor and patch from "v1.29.3-eks-1234" or "containerd 1.6.8".
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+~ "); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return v, false
+	}
+	for i := 0; i < len(parts) && i < 3; i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func versionLess(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// runtimeIssues matches "containerd://1.6.8" against runtimeAdvisories.
+func runtimeIssues(rv string) []string {
+	name, ver, ok := strings.Cut(rv, "://")
+	if !ok {
+		return nil
+	}
+	v, ok := parseVersion(ver)
+	if !ok {
+		return nil
+	}
+	branch := fmt.Sprintf("%d.%d", v[0], v[1])
+	var out []string
+	for _, a := range runtimeAdvisories {
+		if a.runtime != name || (a.branch != "" && a.branch != branch) {
+			continue
+		}
+		if fixed, _ := parseVersion(a.fixed); versionLess(v, fixed) {
+			out = append(out, a.note+" (fixed in "+a.fixed+")")
+		}
+	}
+	return uniqStrings(out)
+}
+
+func osImageMatches(image, prefix string) bool {
+	if !strings.HasPrefix(image, prefix) {
+		return false
+	}
+	rest := image[len(prefix):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '.'
+}
+
+// carrierNAT is RFC 6598 shared address space, used by several clouds for nodes and pods.
+var _, carrierNAT, _ = net.ParseCIDR("100.64.0.0/10")
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || carrierNAT.Contains(ip))
+}
+
+func nodePodCIDRs(n k8s.Node) []string {
+	if len(n.Spec.PodCIDRs) > 0 {
+		return n.Spec.PodCIDRs
+	}
+	if n.Spec.PodCIDR != "" {
+		return []string{n.Spec.PodCIDR}
+	}
+	return nil
+}
@@ -36,35 +36,10 @@ func DetectPodMisconfigs(pods []k8s.Pod, saIndex map[string]k8s.ServiceAccount)
 			})
 		}
 
-		for _, v := range p.Spec.Volumes {
-			if v.HostPath != nil {
-				out = append(out, model.Finding{
-					CheckID:        "K8S-POD-002",
-					Severity:       model.SeverityCritical,
-					Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
-					Title:          "Монтирование hostPath",
-					Evidence:       fmt.Sprintf("volume %q: hostPath=%q", v.Name, v.HostPath.Path),
-					Risk:           "Доступ к ФС узла может привести к эскалации привилегий и утечке данных",
-					Recommendation: "Избегать hostPath. Использовать PVC/CSI или минимально необходимый путь + readOnly",
-				})
-			}
-		}
+		out = append(out, detectHostPaths(p)...)
+		out = append(out, detectPodIsolation(p)...)
 
-		automount := true
-		if p.Spec.AutomountServiceAccountToken != nil {
-			automount = *p.Spec.AutomountServiceAccountToken
-		} else {
-			saName := p.Spec.ServiceAccountName
-			if saName == "" {
-				saName = "default"
-			}
-			if sa, ok := saIndex[ns+"/"+saName]; ok {
-				if sa.AutomountServiceAccountToken != nil {
-					automount = *sa.AutomountServiceAccountToken
-				}
-			}
-		}
-		if automount {
+		if tokenAutomounted(p, saIndex) {
 			out = append(out, model.Finding{
 				CheckID:        "K8S-POD-008",
 				Severity:       model.SeverityMedium,
@@ -180,30 +155,8 @@ func DetectPodMisconfigs(pods []k8s.Pod, saIndex map[string]k8s.ServiceAccount)
 				})
 			}
 
-			if ctx != nil && ctx.Capabilities != nil {
-				if len(ctx.Capabilities.Add) > 0 {
-					out = append(out, model.Finding{
-						CheckID:        "K8S-POD-010",
-						Severity:       model.SeverityMedium,
-						Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
-						Title:          "Добавлены Linux capabilities",
-						Evidence:       fmt.Sprintf("container %q: capabilities.add=%v", ctn.Name, ctx.Capabilities.Add),
-						Risk:           "Доп. capabilities увеличивают привилегии контейнера",
-						Recommendation: "Избегать capabilities.add, использовать allowlist по необходимости",
-					})
-				}
-				if len(ctx.Capabilities.Drop) == 0 {
-					out = append(out, model.Finding{
-						CheckID:        "K8S-POD-011",
-						Severity:       model.SeverityLow,
-						Resource:       model.ResourceRef{Kind: "Pod", Namespace: ns, Name: name},
-						Title:          "Не указан capabilities.drop",
-						Evidence:       fmt.Sprintf("container %q: capabilities.drop not set", ctn.Name),
-						Risk:           "По умолчанию контейнер сохраняет набор стандартных capabilities",
-						Recommendation: "Явно сбросить все capabilities (drop: [\"ALL\"]) и добавить нужные",
-					})
-				}
-			}
+			out = append(out, detectCapabilities(p, ctn)...)
+			out = append(out, detectContainerIsolation(p, ctn)...)
 
 			for _, ev := range ctn.Env {
 				if ev.ValueFrom != nil && ev.ValueFrom.SecretKeyRef != nil {
@@ -235,3 +188,23 @@ func DetectPodMisconfigs(pods []k8s.Pod, saIndex map[string]k8s.ServiceAccount)
 	}
 	return out
 }
+
+func podServiceAccount(p k8s.Pod) string {
+	if p.Spec.ServiceAccountName == "" {
+		return "default"
+	}
+	return p.Spec.ServiceAccountName
+}
+
+// tokenAutomounted resolves automountServiceAccountToken from the pod, then the SA.
+func tokenAutomounted(p k8s.Pod, saIndex map[string]k8s.ServiceAccount) bool {
+	if p.Spec.AutomountServiceAccountToken != nil {
+		return *p.Spec.AutomountServiceAccountToken
+	}
+	if sa, ok := saIndex[p.Metadata.Namespace+"/"+podServiceAccount(p)]; ok {
+		if sa.AutomountServiceAccountToken != nil {
+			return *sa.AutomountServiceAccountToken
+		}
+	}
+	return true
+}
@@ -21,6 +21,8 @@ type BoundRole struct {
 
 type EffectiveRBAC struct {
 	BySA map[string][]BoundRole
+	// BySubject holds User and Group subjects, keyed "User/name" or "Group/name".
+	BySubject map[string][]BoundRole
 }
 
 func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s.ClusterRole, rbs []k8s.RoleBinding, crbs []k8s.ClusterRoleBinding) EffectiveRBAC {
@@ -34,6 +36,7 @@ func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s
 	}
 
 	bySA := map[string][]BoundRole{}
+	bySubject := map[string][]BoundRole{}
 	add := func(saNS, saName string, br BoundRole) {
 		key := saNS + "/" + saName
 		bySA[key] = append(bySA[key], br)
@@ -42,13 +45,6 @@ func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s
 	for _, rb := range rbs {
 		bNS := rb.Metadata.Namespace
 		for _, sub := range rb.Subjects {
-			if sub.Kind != "ServiceAccount" {
-				continue
-			}
-			saNS := sub.Namespace
-			if saNS == "" {
-				saNS = bNS
-			}
 			br := BoundRole{
 				RoleKind:  rb.RoleRef.Kind,
 				RoleName:  rb.RoleRef.Name,
@@ -65,16 +61,21 @@ func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s
 					br.Rules = cr.Rules
 				}
 			}
+			if sub.Kind != "ServiceAccount" {
+				bySubject[sub.Kind+"/"+sub.Name] = append(bySubject[sub.Kind+"/"+sub.Name], br)
+				continue
+			}
+			saNS := sub.Namespace
+			if saNS == "" {
+				saNS = bNS
+			}
 			add(saNS, sub.Name, br)
 		}
 	}
 
 	for _, crb := range crbs {
 		for _, sub := range crb.Subjects {
-			if sub.Kind != "ServiceAccount" {
-				continue
-			}
-			if sub.Namespace == "" {
+			if sub.Kind == "ServiceAccount" && sub.Namespace == "" {
 				continue
 			}
 			br := BoundRole{
@@ -88,6 +89,10 @@ func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s
 					br.Rules = cr.Rules
 				}
 			}
+			if sub.Kind != "ServiceAccount" {
+				bySubject[sub.Kind+"/"+sub.Name] = append(bySubject[sub.Kind+"/"+sub.Name], br)
+				continue
+			}
 			add(sub.Namespace, sub.Name, br)
 		}
 	}
@@ -98,7 +103,7 @@ func BuildEffectiveRBAC(sas []k8s.ServiceAccount, roles []k8s.Role, cRoles []k8s
 			bySA[key] = nil
 		}
 	}
-	return EffectiveRBAC{BySA: bySA}
+	return EffectiveRBAC{BySA: bySA, BySubject: bySubject}
 }
 
 func DetectRBAC(e EffectiveRBAC) []model.Finding {
@@ -210,3 +215,56 @@ func DetectClusterRolesDirect(clusterRoles []k8s.ClusterRole) []model.Finding {
 	}
 	return out
 }
+
+// dangerousGrants summarises the grants DetectRBAC would flag for a SA.
+func dangerousGrants(bound []BoundRole) []string {
+	var out []string
+	for _, br := range bound {
+		if br.RoleKind == "ClusterRole" && br.RoleName == "cluster-admin" {
+			out = append(out, "cluster-admin")
+		}
+		for _, rule := range br.Rules {
+			res := uniqStrings(rule.Resources)
+			verbs := uniqStrings(rule.Verbs)
+			if hasStar(rule.Verbs) || hasStar(rule.Resources) || hasStar(rule.APIGroups) {
+				out = append(out, "wildcard via "+strings.ToLower(br.RoleKind)+"/"+br.RoleName)
+			}
+			if containsAny(res, "secrets") && containsAny(verbs, "get", "list", "watch") {
+				out = append(out, "read secrets")
+			}
+			if containsAny(res, "pods/exec") && containsAny(verbs, "create", "get") {
+				out = append(out, "pods/exec")
+			}
+			if containsAny(res, "rolebindings", "clusterrolebindings") && containsAny(verbs, "create", "patch", "update") {
+				out = append(out, "modify role bindings")
+			}
+		}
+	}
+	return uniqStrings(out)
+}
+
+// canRead reports whether bound roles allow get or list of a core resource in ns.
+func canRead(bound []BoundRole, ns, resource string) bool {
+	return allows(bound, ns, resource, "", "get", "list")
+}
+
+// allows reports whether bound roles grant any of verbs on a core resource in ns.
+// With name == "" rules restricted by resourceNames are ignored.
+func allows(bound []BoundRole, ns, resource, name string, verbs ...string) bool {
+	for _, br := range bound {
+		if br.BindingNS != "(cluster)" && br.BindingNS != ns {
+			continue
+		}
+		for _, rule := range br.Rules {
+			if len(rule.ResourceNames) > 0 && (name == "" || !containsAny(rule.ResourceNames, name)) {
+				continue
+			}
+			if (containsAny(rule.APIGroups, "") || hasStar(rule.APIGroups)) &&
+				(containsAny(rule.Resources, resource) || hasStar(rule.Resources)) &&
+				(containsAny(rule.Verbs, verbs...) || hasStar(rule.Verbs)) {
+				return true
+			}
+		}
+	}
+	return false
+}
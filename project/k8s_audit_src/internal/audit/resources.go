@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// ResourceInputs holds what the resource-governance checks need.
+type ResourceInputs struct {
+	Namespaces     []k8s.Namespace
+	Pods           []k8s.Pod
+	LimitRanges    []k8s.LimitRange
+	ResourceQuotas []k8s.ResourceQuota
+	// Listed is false when LimitRanges/ResourceQuotas could not be listed; namespace checks are skipped.
+	Listed bool
+}
+
+// quotaCounts are object counts a quota should cap; either key form counts.
+var quotaCounts = []struct {
+	name string
+	keys []string
+}{
+	{"pods", []string{"pods", "count/pods"}},
+	{"services", []string{"services", "count/services"}},
+	{"services.loadbalancers", []string{"services.loadbalancers"}},
+	{"secrets", []string{"secrets", "count/secrets"}},
+}
+
+// DetectResourceGovernance reports containers without requests/limits and
+// namespaces without LimitRange/ResourceQuota guard rails.
+func DetectResourceGovernance(in ResourceInputs) []model.Finding {
+	var out []model.Finding
+	for _, p := range in.Pods {
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			if f, ok := detectContainerResources(p, c); ok {
+				out = append(out, f)
+			}
+		}
+	}
+	if !in.Listed {
+		return out
+	}
+
+	lrByNS := map[string][]k8s.LimitRange{}
+	for _, lr := range in.LimitRanges {
+		lrByNS[lr.Metadata.Namespace] = append(lrByNS[lr.Metadata.Namespace], lr)
+	}
+	quotaByNS := map[string][]k8s.ResourceQuota{}
+	for _, q := range in.ResourceQuotas {
+		quotaByNS[q.Metadata.Namespace] = append(quotaByNS[q.Metadata.Namespace], q)
+	}
+
+	for _, ns := range in.Namespaces {
+		name := ns.Metadata.Name
+		if isSystemNamespace(name) {
+			continue
+		}
+		res := model.ResourceRef{Kind: "Namespace", Name: name}
+
+		lrs := lrByNS[name]
+		if len(lrs) == 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-RES-002",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "В namespace нет LimitRange",
+				Evidence:       "no LimitRange objects in namespace",
+				Risk:           "Контейнеры без явных limits не получают значений по умолчанию и могут занять все ресурсы узла",
+				Recommendation: "Создать LimitRange с default/defaultRequest и max для cpu и memory контейнеров",
+			})
+		} else if !limitRangeCapsMemory(lrs) {
+			var names []string
+			for _, lr := range lrs {
+				names = append(names, lr.Metadata.Name)
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-RES-002",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "LimitRange не ограничивает память контейнеров",
+				Evidence:       fmt.Sprintf("limitrange %s: no Container default or max for memory", strings.Join(names, ", ")),
+				Risk:           "Контейнеры без явного memory limit остаются неограниченными несмотря на LimitRange",
+				Recommendation: "Задать default и max для memory в элементе type: Container",
+			})
+		}
+
+		quotas := quotaByNS[name]
+		if len(quotas) == 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-RES-003",
+				Severity:       model.SeverityMedium,
+				Resource:       res,
+				Title:          "В namespace нет ResourceQuota",
+				Evidence:       "no ResourceQuota objects in namespace",
+				Risk:           "Скомпрометированная или ошибочная нагрузка может создать неограниченное число Pod'ов, Service и Secret и исчерпать ресурсы кластера",
+				Recommendation: "Создать ResourceQuota с лимитами на compute-ресурсы и количество объектов",
+			})
+			continue
+		}
+		hard := map[string]struct{}{}
+		for _, q := range quotas {
+			for k := range q.Spec.Hard {
+				hard[k] = struct{}{}
+			}
+		}
+		var missing []string
+		for _, qc := range quotaCounts {
+			capped := false
+			for _, k := range qc.keys {
+				if _, ok := hard[k]; ok {
+					capped = true
+				}
+			}
+			if !capped {
+				missing = append(missing, qc.name)
+			}
+		}
+		if len(missing) > 0 {
+			var set []string
+			for k := range hard {
+				set = append(set, k)
+			}
+			sort.Strings(set)
+			out = append(out, model.Finding{
+				CheckID:        "K8S-RES-004",
+				Severity:       model.SeverityLow,
+				Resource:       res,
+				Title:          "ResourceQuota не ограничивает количество объектов",
+				Evidence:       fmt.Sprintf("not capped: %s; quota hard keys: %s", strings.Join(missing, ", "), strings.Join(set, ", ")),
+				Risk:           "Массовое создание Pod'ов, LoadBalancer'ов или Secret'ов ведет к отказу в обслуживании и лишним расходам",
+				Recommendation: "Добавить в ResourceQuota hard-лимиты pods, services, services.loadbalancers и secrets",
+			})
+		}
+	}
+	return out
+}
+
+func detectContainerResources(p k8s.Pod, c k8s.Container) (model.Finding, bool) {
+	var missing []string
+	for _, r := range []string{"cpu", "memory"} {
+		if _, ok := c.Resources.Requests[r]; !ok {
+			missing = append(missing, "requests."+r)
+		}
+	}
+	for _, r := range []string{"cpu", "memory", "ephemeral-storage"} {
+		if _, ok := c.Resources.Limits[r]; !ok {
+			missing = append(missing, "limits."+r)
+		}
+	}
+	if len(missing) == 0 {
+		return model.Finding{}, false
+	}
+	// an unlimited memory consumer evicts or OOM-kills its neighbours
+	sev := model.SeverityLow
+	if containsAny(missing, "limits.memory") {
+		sev = model.SeverityMedium
+	}
+	return model.Finding{
+		CheckID:        "K8S-RES-001",
+		Severity:       sev,
+		Resource:       model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name},
+		Title:          "Не заданы requests/limits контейнера",
+		Evidence:       fmt.Sprintf("container %q: missing %s", c.Name, strings.Join(missing, ", ")),
+		Risk:           "Контейнер без лимитов может исчерпать CPU, память или диск узла и вызвать отказ соседних Pod'ов",
+		Recommendation: "Задать requests и limits для cpu, memory и ephemeral-storage (или значения по умолчанию через LimitRange)",
+	}, true
+}
+
+func limitRangeCapsMemory(lrs []k8s.LimitRange) bool {
+	for _, lr := range lrs {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != "Container" {
+				continue
+			}
+			if _, ok := item.Default["memory"]; ok {
+				return true
+			}
+			if _, ok := item.Max["memory"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
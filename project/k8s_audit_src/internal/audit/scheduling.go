@@ -0,0 +1,339 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+var controlPlaneRoleKeys = []string{"node-role.kubernetes.io/control-plane", "node-role.kubernetes.io/master"}
+
+// defaultControlPlaneTaints are assumed when nodes cannot be listed.
+var defaultControlPlaneTaints = []k8s.Taint{
+	{Key: "node-role.kubernetes.io/control-plane", Effect: "NoSchedule"},
+}
+
+func isControlPlaneNode(n k8s.Node) bool {
+	for _, k := range controlPlaneRoleKeys {
+		if _, ok := n.Metadata.Labels[k]; ok {
+			return true
+		}
+	}
+	for _, t := range n.Spec.Taints {
+		if containsAny(controlPlaneRoleKeys, t.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectControlPlaneScheduling reports workloads that can run on (or already run on)
+// control-plane nodes and control-plane nodes that accept ordinary workloads.
+// nodes is nil when they could not be listed; default kubeadm taints are assumed then.
+func DetectControlPlaneScheduling(pods []k8s.Pod, nodes []k8s.Node) []model.Finding {
+	var out []model.Finding
+	var cp []k8s.Node
+	for _, n := range nodes {
+		if isControlPlaneNode(n) {
+			cp = append(cp, n)
+		}
+	}
+
+	for _, n := range cp {
+		if hasSchedulingTaint(n) || n.Spec.Unschedulable {
+			continue
+		}
+		sev := model.SeverityHigh
+		ev := fmt.Sprintf("node %q has a control-plane role but no NoSchedule/NoExecute taint", n.Metadata.Name)
+		if len(nodes) == len(cp) {
+			sev = model.SeverityMedium
+			ev += " (no worker nodes)"
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-SCHED-002",
+			Severity:       sev,
+			Resource:       model.ResourceRef{Kind: "Node", Name: n.Metadata.Name},
+			Title:          "Control-plane узел принимает обычные нагрузки",
+			Evidence:       ev,
+			Risk:           "Любой Pod может быть запланирован рядом с etcd, kube-apiserver и их ключами; выход из контейнера компрометирует кластер",
+			Recommendation: "Вернуть taint node-role.kubernetes.io/control-plane:NoSchedule",
+		})
+	}
+
+	cpNames := map[string]struct{}{}
+	for _, n := range cp {
+		cpNames[n.Metadata.Name] = struct{}{}
+	}
+	targets := cp
+	if nodes == nil {
+		targets = []k8s.Node{{
+			Metadata: k8s.ObjectMeta{Labels: map[string]string{controlPlaneRoleKeys[0]: ""}},
+			Spec:     k8s.NodeSpec{Taints: defaultControlPlaneTaints},
+		}}
+	}
+
+	for _, p := range pods {
+		if isSystemNamespace(p.Metadata.Namespace) {
+			continue
+		}
+		var reasons []string
+		_, running := cpNames[p.Spec.NodeName]
+		if running {
+			reasons = append(reasons, fmt.Sprintf("runs on control-plane node %q", p.Spec.NodeName))
+		}
+
+		schedulable := false
+		for _, n := range targets {
+			if (nodes == nil || nodeSelectable(p, n)) && !excludesControlPlane(p) && toleratesNode(p, n) {
+				schedulable = true
+				break
+			}
+		}
+		// without a taint to tolerate every pod is schedulable; SCHED-002 covers that
+		tol := controlPlaneTolerations(p)
+		if !running && (!schedulable || len(tol) == 0) {
+			continue
+		}
+		if len(tol) > 0 {
+			reasons = append(reasons, "tolerates "+strings.Join(tol, ", "))
+		}
+		if sel := targetsControlPlane(p, cpNames); sel != "" {
+			reasons = append(reasons, "selects control-plane nodes via "+sel)
+		}
+
+		danger := dangerousPodTraits(p)
+		sev := model.SeverityMedium
+		if len(danger) > 0 {
+			sev = model.SeverityHigh
+			reasons = append(reasons, "pod has "+strings.Join(danger, ", "))
+		}
+		if running {
+			sev = raiseSeverity(sev)
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-SCHED-001",
+			Severity:       sev,
+			Resource:       model.ResourceRef{Kind: "Pod", Namespace: p.Metadata.Namespace, Name: p.Metadata.Name},
+			Title:          "Pod может выполняться на control-plane узлах",
+			Evidence:       strings.Join(reasons, "; "),
+			Risk:           "Компрометация такого Pod'а (особенно privileged или с hostPath) дает доступ к etcd, ключам и сертификатам control plane",
+			Recommendation: "Убрать toleration для control-plane (и wildcard operator: Exists), не выбирать control-plane узлы через nodeSelector/affinity",
+		})
+	}
+	return out
+}
+
+func hasSchedulingTaint(n k8s.Node) bool {
+	for _, t := range n.Spec.Taints {
+		if t.Effect == "NoSchedule" || t.Effect == "NoExecute" {
+			return true
+		}
+	}
+	return false
+}
+
+func toleratesTaint(t k8s.Toleration, taint k8s.Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Operator == "Exists" {
+		return t.Key == "" || t.Key == taint.Key
+	}
+	return t.Key == taint.Key && t.Value == taint.Value
+}
+
+// toleratesNode reports whether every NoSchedule/NoExecute taint of n is tolerated.
+func toleratesNode(p k8s.Pod, n k8s.Node) bool {
+	for _, taint := range n.Spec.Taints {
+		if taint.Effect != "NoSchedule" && taint.Effect != "NoExecute" {
+			continue
+		}
+		ok := false
+		for _, t := range p.Spec.Tolerations {
+			if toleratesTaint(t, taint) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// controlPlaneTolerations describes tolerations matching the control-plane taints.
+func controlPlaneTolerations(p k8s.Pod) []string {
+	var out []string
+	for _, t := range p.Spec.Tolerations {
+		switch {
+		case t.Operator == "Exists" && t.Key == "":
+			s := "everything (operator: Exists without key)"
+			if t.Effect != "" {
+				s = fmt.Sprintf("every %s taint (operator: Exists without key)", t.Effect)
+			}
+			if t.Effect == "" || t.Effect == "NoSchedule" || t.Effect == "NoExecute" {
+				out = append(out, s)
+			}
+		case containsAny(controlPlaneRoleKeys, t.Key) && (t.Effect == "" || t.Effect == "NoSchedule"):
+			effect := t.Effect
+			if effect == "" {
+				effect = "*"
+			}
+			out = append(out, t.Key+":"+effect)
+		}
+	}
+	return out
+}
+
+// nodeSelectable evaluates nodeSelector and required node affinity against n.
+func nodeSelectable(p k8s.Pod, n k8s.Node) bool {
+	if !labelsMatch(p.Spec.NodeSelector, n.Metadata.Labels) {
+		return false
+	}
+	req := requiredNodeSelector(p)
+	if req == nil || len(req.NodeSelectorTerms) == 0 {
+		return true
+	}
+	for _, term := range req.NodeSelectorTerms {
+		if nodeTermMatches(term, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func requiredNodeSelector(p k8s.Pod) *k8s.NodeSelector {
+	if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil {
+		return nil
+	}
+	return p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+// nodeTermMatches evaluates matchExpressions against the node labels and matchFields
+// against its fields; metadata.name is the only field the API supports.
+func nodeTermMatches(term k8s.NodeSelectorTerm, n k8s.Node) bool {
+	return requirementsMatch(term.MatchExpressions, n.Metadata.Labels) &&
+		requirementsMatch(term.MatchFields, map[string]string{"metadata.name": n.Metadata.Name})
+}
+
+func requirementsMatch(reqs []k8s.NodeSelectorRequirement, values map[string]string) bool {
+	for _, e := range reqs {
+		v, has := values[e.Key]
+		switch e.Operator {
+		case "In":
+			if !has || !containsAny(e.Values, v) {
+				return false
+			}
+		case "NotIn":
+			if has && containsAny(e.Values, v) {
+				return false
+			}
+		case "Exists":
+			if !has {
+				return false
+			}
+		case "DoesNotExist":
+			if has {
+				return false
+			}
+		case "Gt", "Lt":
+			if !has || len(e.Values) != 1 {
+				return false
+			}
+			a, err1 := strconv.ParseInt(v, 10, 64)
+			b, err2 := strconv.ParseInt(e.Values[0], 10, 64)
+			if err1 != nil || err2 != nil || (e.Operator == "Gt" && a <= b) || (e.Operator == "Lt" && a >= b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// excludesControlPlane is true when every required affinity term keeps the pod off control-plane nodes.
+func excludesControlPlane(p k8s.Pod) bool {
+	req := requiredNodeSelector(p)
+	if req == nil || len(req.NodeSelectorTerms) == 0 {
+		return false
+	}
+	for _, term := range req.NodeSelectorTerms {
+		excluded := false
+		for _, e := range term.MatchExpressions {
+			if containsAny(controlPlaneRoleKeys, e.Key) && (e.Operator == "DoesNotExist" || (e.Operator == "NotIn" && containsAny(e.Values, ""))) {
+				excluded = true
+			}
+		}
+		if !excluded {
+			return false
+		}
+	}
+	return true
+}
+
+// targetsControlPlane returns how the pod explicitly asks for control-plane nodes, if it does.
+func targetsControlPlane(p k8s.Pod, cpNames map[string]struct{}) string {
+	for _, k := range controlPlaneRoleKeys {
+		if _, ok := p.Spec.NodeSelector[k]; ok {
+			return "nodeSelector " + k
+		}
+	}
+	if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil {
+		return ""
+	}
+	na := p.Spec.Affinity.NodeAffinity
+	var terms []k8s.NodeSelectorTerm
+	if na.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		terms = append(terms, na.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms...)
+	}
+	for _, pt := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+		terms = append(terms, pt.Preference)
+	}
+	for _, term := range terms {
+		for _, e := range term.MatchExpressions {
+			if containsAny(controlPlaneRoleKeys, e.Key) && (e.Operator == "Exists" || e.Operator == "In") {
+				return "nodeAffinity " + e.Key + " " + e.Operator
+			}
+		}
+		for _, f := range term.MatchFields {
+			if f.Key != "metadata.name" || f.Operator != "In" {
+				continue
+			}
+			for _, name := range f.Values {
+				if _, ok := cpNames[name]; ok {
+					return "nodeAffinity metadata.name In " + name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// dangerousPodTraits lists what makes a pod on a control-plane node a cluster compromise.
+func dangerousPodTraits(p k8s.Pod) []string {
+	var out []string
+	containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+	containers = append(containers, p.Spec.Containers...)
+	for _, c := range containers {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+			out = append(out, "privileged container "+strconv.Quote(c.Name))
+		}
+	}
+	for _, v := range p.Spec.Volumes {
+		if v.HostPath != nil {
+			out = append(out, "hostPath "+v.HostPath.Path)
+		}
+	}
+	if p.Spec.HostNetwork {
+		out = append(out, "hostNetwork")
+	}
+	if p.Spec.HostPID {
+		out = append(out, "hostPID")
+	}
+	sort.Strings(out)
+	return out
+}
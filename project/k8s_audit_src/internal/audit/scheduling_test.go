@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/k8s"
+)
+
+func TestSchedulingMatchFields(t *testing.T) {
+	nodes := []k8s.Node{
+		{
+			Metadata: k8s.ObjectMeta{Name: "cp-1", Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}},
+			Spec:     k8s.NodeSpec{Taints: []k8s.Taint{{Key: "node-role.kubernetes.io/control-plane", Effect: "NoSchedule"}}},
+		},
+		{Metadata: k8s.ObjectMeta{Name: "worker-1", Labels: map[string]string{"kubernetes.io/os": "linux"}}},
+	}
+	pod := func(fields ...k8s.NodeSelectorRequirement) k8s.Pod {
+		return k8s.Pod{
+			Metadata: k8s.ObjectMeta{Name: "agent", Namespace: "ops"},
+			Spec: k8s.PodSpec{
+				Tolerations: []k8s.Toleration{{Key: "node-role.kubernetes.io/control-plane", Operator: "Exists", Effect: "NoSchedule"}},
+				Affinity: &k8s.Affinity{NodeAffinity: &k8s.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &k8s.NodeSelector{
+						NodeSelectorTerms: []k8s.NodeSelectorTerm{{MatchFields: fields}},
+					},
+				}},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		fields []k8s.NodeSelectorRequirement
+		want   string // evidence fragment, "" for no finding
+	}{
+		{"pinned to a worker", []k8s.NodeSelectorRequirement{{Key: "metadata.name", Operator: "In", Values: []string{"worker-1"}}}, ""},
+		{"pinned to control plane", []k8s.NodeSelectorRequirement{{Key: "metadata.name", Operator: "In", Values: []string{"cp-1"}}}, "metadata.name In cp-1"},
+		{"control plane excluded by name", []k8s.NodeSelectorRequirement{{Key: "metadata.name", Operator: "NotIn", Values: []string{"cp-1"}}}, ""},
+		{"any node", nil, "tolerates node-role.kubernetes.io/control-plane:NoSchedule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectControlPlaneScheduling([]k8s.Pod{pod(tt.fields...)}, nodes)
+			var sched []string
+			for _, f := range got {
+				if f.CheckID == "K8S-SCHED-001" {
+					sched = append(sched, f.Evidence)
+				}
+			}
+			switch {
+			case tt.want == "" && len(sched) > 0:
+				t.Errorf("unexpected finding: %v", sched)
+			case tt.want != "" && (len(sched) != 1 || !strings.Contains(sched[0], tt.want)):
+				t.Errorf("got %v, want evidence with %q", sched, tt.want)
+			}
+		})
+	}
+}
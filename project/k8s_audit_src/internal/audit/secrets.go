@@ -0,0 +1,367 @@
+package audit
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Secret values must never reach the report: evidence below only names keys,
+// hosts and certificate metadata.
+
+const certExpiryWarning = 30 * 24 * time.Hour
+
+var weakPasswords = map[string]struct{}{
+	"password": {}, "passw0rd": {}, "p@ssw0rd": {}, "admin": {}, "administrator": {}, "root": {}, "toor": {},
+	"changeme": {}, "changeit": {}, "secret": {}, "default": {}, "guest": {}, "test": {}, "letmein": {},
+	"welcome": {}, "qwerty": {}, "123456": {}, "12345678": {}, "123456789": {}, "1234567890": {},
+	"postgres": {}, "mysql": {}, "redis": {}, "mongo": {}, "rabbitmq": {}, "elastic": {}, "minioadmin": {},
+}
+
+var passwordKey = []string{"password", "passwd", "pwd", "pass"}
+
+// passwordSuffix may end a run-together token (PGPASSWORD, rootpassword); "pass" may not (bypass, compass).
+var passwordSuffix = []string{"password", "passwd"}
+
+// publicRegistries are registries reachable from the internet, where leaked pull credentials are usable.
+var publicRegistries = []string{
+	"docker.io", "index.docker.io", "registry-1.docker.io", "ghcr.io", "quay.io", "gcr.io", "pkg.dev",
+	"registry.gitlab.com", "public.ecr.aws", "amazonaws.com", "azurecr.io", "nvcr.io",
+}
+
+// DetectSecretContents analyses Secret payloads (opt-in, see -analyze-secrets).
+func DetectSecretContents(namespaces []k8s.Namespace, secrets []k8s.Secret, sas []k8s.ServiceAccount) []model.Finding {
+	allowed := map[string]struct{}{}
+	for _, ns := range namespaces {
+		allowed[ns.Metadata.Name] = struct{}{}
+	}
+	saIndex := map[string]struct{}{}
+	for _, sa := range sas {
+		saIndex[sa.Metadata.Namespace+"/"+sa.Metadata.Name] = struct{}{}
+	}
+	now := time.Now()
+
+	var out []model.Finding
+	for _, s := range secrets {
+		ns := s.Metadata.Namespace
+		if _, ok := allowed[ns]; !ok {
+			continue
+		}
+		res := model.ResourceRef{Kind: "Secret", Namespace: ns, Name: s.Metadata.Name}
+		keys := make([]string, 0, len(s.Data))
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		switch s.Type {
+		case "kubernetes.io/service-account-token":
+			sa := s.Metadata.Annotations["kubernetes.io/service-account.name"]
+			sev := model.SeverityLow
+			ev := fmt.Sprintf("type=kubernetes.io/service-account-token serviceAccount=%q", sa)
+			if _, ok := saIndex[ns+"/"+sa]; !ok && len(sas) > 0 {
+				sev = model.SeverityMedium
+				ev += " (ServiceAccount no longer exists)"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SEC-005",
+				Severity:       sev,
+				Resource:       res,
+				Title:          "Legacy-токен ServiceAccount в Secret",
+				Evidence:       ev,
+				Risk:           "Бессрочный токен без привязки к Pod'у: утечка дает постоянный доступ к API",
+				Recommendation: "Удалить Secret и использовать projected-токены (TokenRequest) с ограниченным сроком",
+			})
+			continue
+		case "kubernetes.io/dockerconfigjson", "kubernetes.io/dockercfg":
+			if hosts := publicRegistryAuths(s); len(hosts) > 0 {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-SEC-003",
+					Severity:       model.SeverityMedium,
+					Resource:       res,
+					Title:          "Учетные данные публичного registry в pull-секрете",
+					Evidence:       fmt.Sprintf("credentials for: %s", strings.Join(hosts, ", ")),
+					Risk:           "Учетная запись публичного registry часто имеет права push: утечка позволяет подменить образы",
+					Recommendation: "Использовать read-only токены или зеркало в приватном registry; ограничить чтение секрета",
+				})
+			}
+		}
+
+		for _, k := range keys {
+			v := s.Data[k]
+			if isPasswordKey(k) {
+				if reason := weakPassword(v, s.Data); reason != "" {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-SEC-001",
+						Severity:       model.SeverityHigh,
+						Resource:       res,
+						Title:          "Слабый или стандартный пароль в Secret",
+						Evidence:       fmt.Sprintf("key %q: %s", k, reason),
+						Risk:           "Пароль подбирается без доступа к секрету",
+						Recommendation: "Сгенерировать стойкий случайный пароль и ротировать учетные данные",
+					})
+				}
+			}
+			if s.Type != "kubernetes.io/tls" {
+				if typ := unencryptedPrivateKey(v); typ != "" {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-SEC-002",
+						Severity:       model.SeverityMedium,
+						Resource:       res,
+						Title:          "Незашифрованный закрытый ключ в Secret",
+						Evidence:       fmt.Sprintf("key %q: %s without passphrase", k, typ),
+						Risk:           "Любой, кто может прочитать Secret, получает готовый к использованию ключ",
+						Recommendation: "Хранить ключ во внешнем KMS/Vault или зашифровать его; ограничить чтение секрета",
+					})
+				}
+			}
+			if s.Type == "kubernetes.io/tls" && k != "tls.crt" && k != "ca.crt" {
+				continue
+			}
+			for _, c := range parseCertificates(v) {
+				for _, p := range certProblems(c, now) {
+					out = append(out, model.Finding{
+						CheckID:        "K8S-SEC-004",
+						Severity:       p.sev,
+						Resource:       res,
+						Title:          p.title,
+						Evidence:       fmt.Sprintf("key %q: subject=%q notAfter=%s %s", k, c.Subject.String(), c.NotAfter.UTC().Format(time.RFC3339), p.detail),
+						Risk:           p.risk,
+						Recommendation: "Перевыпустить сертификат (RSA>=2048 или ECDSA P-256+, SHA-256) и настроить автоматическое обновление",
+					})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// isPasswordKey matches password words as tokens of the key, split on "_", "-", "."
+// and camelCase boundaries: DB_PASSWORD, dbPass, admin.pwd, but not bypass or passthrough.
+func isPasswordKey(k string) bool {
+	lower := strings.ToLower(k)
+	if strings.Contains(lower, "file") || strings.Contains(lower, "hash") {
+		return false
+	}
+	for _, t := range keyTokens(k) {
+		if containsAny(passwordKey, t) {
+			return true
+		}
+		for _, p := range passwordSuffix {
+			if strings.HasSuffix(t, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyTokens splits a key into lower-case words: "dbPassword_v2" -> [db password v2].
+func keyTokens(k string) []string {
+	var out []string
+	var cur []rune
+	prevLower := false
+	for _, r := range k {
+		if r == '_' || r == '-' || r == '.' {
+			if len(cur) > 0 {
+				out = append(out, string(cur))
+			}
+			cur, prevLower = nil, false
+			continue
+		}
+		if unicode.IsUpper(r) && prevLower {
+			out = append(out, string(cur))
+			cur = nil
+		}
+		cur = append(cur, unicode.ToLower(r))
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	if len(cur) > 0 {
+		out = append(out, string(cur))
+	}
+	return out
+}
+
+// weakPassword returns why v is weak ("" if it is not). The value itself is never returned.
+func weakPassword(v []byte, data map[string][]byte) string {
+	pw := strings.TrimSpace(string(v))
+	if pw == "" {
+		return "empty password"
+	}
+	if _, ok := weakPasswords[strings.ToLower(pw)]; ok {
+		return "value is a well-known default password"
+	}
+	for k, u := range data {
+		if lk := strings.ToLower(k); (strings.Contains(lk, "user") || strings.Contains(lk, "login")) && strings.EqualFold(strings.TrimSpace(string(u)), pw) {
+			return fmt.Sprintf("password equals the value of key %q", k)
+		}
+	}
+	if len(pw) < 8 {
+		return "shorter than 8 characters"
+	}
+	return ""
+}
+
+// unencryptedPrivateKey returns the PEM type of the first private key without encryption.
+func unencryptedPrivateKey(v []byte) string {
+	rest := v
+	for {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			return ""
+		}
+		if !strings.HasSuffix(b.Type, "PRIVATE KEY") || b.Type == "ENCRYPTED PRIVATE KEY" {
+			continue
+		}
+		if strings.Contains(b.Headers["Proc-Type"], "ENCRYPTED") {
+			continue
+		}
+		if b.Type == "OPENSSH PRIVATE KEY" && opensshEncrypted(b.Bytes) {
+			continue
+		}
+		return b.Type
+	}
+}
+
+// opensshEncrypted checks the cipher name of an openssh-key-v1 blob.
+func opensshEncrypted(b []byte) bool {
+	const magic = "openssh-key-v1\x00"
+	if len(b) < len(magic)+4 || string(b[:len(magic)]) != magic {
+		return false
+	}
+	b = b[len(magic):]
+	n := int(b[0])<<24 | int(b[1])<<16 | int(b[2])<<8 | int(b[3])
+	if n > len(b)-4 {
+		return false
+	}
+	return string(b[4:4+n]) != "none"
+}
+
+func publicRegistryAuths(s k8s.Secret) []string {
+	var auths map[string]struct {
+		Auth     string `json:"auth"`
+		Password string `json:"password"`
+	}
+	if raw, ok := s.Data[".dockerconfigjson"]; ok {
+		var cfg struct {
+			Auths map[string]struct {
+				Auth     string `json:"auth"`
+				Password string `json:"password"`
+			} `json:"auths"`
+		}
+		if json.Unmarshal(raw, &cfg) != nil {
+			return nil
+		}
+		auths = cfg.Auths
+	} else if raw, ok := s.Data[".dockercfg"]; ok {
+		if json.Unmarshal(raw, &auths) != nil {
+			return nil
+		}
+	}
+	var out []string
+	for host, a := range auths {
+		if a.Password == "" && !basicAuthHasPassword(a.Auth) {
+			continue
+		}
+		if isPublicRegistry(host) {
+			out = append(out, host)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func basicAuthHasPassword(auth string) bool {
+	b, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return false
+	}
+	_, pw, ok := strings.Cut(string(b), ":")
+	return ok && pw != ""
+}
+
+func isPublicRegistry(host string) bool {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	host, _, _ = strings.Cut(host, ":")
+	for _, r := range publicRegistries {
+		if host == r || strings.HasSuffix(host, "."+r) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCertificates(v []byte) []*x509.Certificate {
+	var out []*x509.Certificate
+	rest := v
+	for {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			return out
+		}
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+		if c, err := x509.ParseCertificate(b.Bytes); err == nil {
+			out = append(out, c)
+		}
+	}
+}
+
+type certProblem struct {
+	sev    model.Severity
+	title  string
+	detail string
+	risk   string
+}
+
+func certProblems(c *x509.Certificate, now time.Time) []certProblem {
+	var out []certProblem
+	switch {
+	case now.After(c.NotAfter):
+		out = append(out, certProblem{model.SeverityHigh, "Истек срок действия TLS-сертификата", "(expired)",
+			"Клиенты отклоняют соединения или привыкают игнорировать ошибки проверки сертификата"})
+	case c.NotAfter.Sub(now) < certExpiryWarning:
+		out = append(out, certProblem{model.SeverityMedium, "TLS-сертификат скоро истекает",
+			fmt.Sprintf("(expires in %d days)", int(c.NotAfter.Sub(now).Hours()/24)),
+			"Истечение сертификата приведет к отказу сервиса"})
+	}
+	switch c.PublicKeyAlgorithm {
+	case x509.RSA:
+		if k, ok := c.PublicKey.(*rsa.PublicKey); ok && k.N.BitLen() < 2048 {
+			out = append(out, certProblem{model.SeverityHigh, "Слабый ключ TLS-сертификата", fmt.Sprintf("key=RSA-%d", k.N.BitLen()),
+				"Короткий ключ RSA может быть факторизован"})
+		}
+	case x509.ECDSA:
+		if k, ok := c.PublicKey.(*ecdsa.PublicKey); ok && k.Curve.Params().BitSize < 256 {
+			out = append(out, certProblem{model.SeverityHigh, "Слабый ключ TLS-сертификата", fmt.Sprintf("key=ECDSA-%d", k.Curve.Params().BitSize),
+				"Кривая с недостаточной стойкостью"})
+		}
+	case x509.DSA:
+		out = append(out, certProblem{model.SeverityHigh, "Слабый ключ TLS-сертификата", "key=DSA",
+			"DSA устарел и не поддерживается современными клиентами"})
+	}
+	switch c.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		// self-signed roots are trusted by identity, not by their signature
+		if !(c.IsCA && c.Subject.String() == c.Issuer.String()) {
+			out = append(out, certProblem{model.SeverityMedium, "Устаревший алгоритм подписи TLS-сертификата",
+				"signature=" + c.SignatureAlgorithm.String(), "Подписи MD5/SHA-1 допускают подделку сертификата через коллизии"})
+		}
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package audit
+
+import "testing"
+
+func TestIsPasswordKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"DB_PASSWORD", true},
+		{"mysql-root-password", true},
+		{"PGPASSWORD", true},
+		{"dbPassword", true},
+		{"redisPass", true},
+		{"admin.pwd", true},
+		{"smtp_passwd", true},
+		{"bypass", false},
+		{"compass", false},
+		{"passthrough", false},
+		{"BYPASS_PROXY", false},
+		{"password_file", false},
+		{"passwordHash", false},
+		{"username", false},
+	}
+	for _, tt := range tests {
+		if got := isPasswordKey(tt.key); got != tt.want {
+			t.Errorf("isPasswordKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+type sensitivePort struct {
+	Service  string
+	Severity model.Severity
+}
+
+// Well-known admin/data ports that should never be reachable from outside.
+var sensitivePorts = map[int32]sensitivePort{
+	2375:  {"Docker API (plain)", model.SeverityCritical},
+	2376:  {"Docker API (TLS)", model.SeverityHigh},
+	2379:  {"etcd client", model.SeverityCritical},
+	2380:  {"etcd peer", model.SeverityCritical},
+	6379:  {"Redis", model.SeverityHigh},
+	9200:  {"Elasticsearch", model.SeverityHigh},
+	10250: {"kubelet API", model.SeverityCritical},
+	10255: {"kubelet read-only API", model.SeverityHigh},
+	27017: {"MongoDB", model.SeverityHigh},
+}
+
+// Cloud annotations that make a LoadBalancer internal (VPC-only).
+var internalLBAnnotations = map[string][]string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal":              {"true", "0.0.0.0/0"},
+	"service.beta.kubernetes.io/aws-load-balancer-scheme":                {"internal"},
+	"networking.gke.io/load-balancer-type":                               {"internal"},
+	"cloud.google.com/load-balancer-type":                                {"internal"},
+	"service.beta.kubernetes.io/azure-load-balancer-internal":            {"true"},
+	"service.beta.kubernetes.io/oci-load-balancer-internal":              {"true"},
+	"service.beta.kubernetes.io/openstack-internal-load-balancer":        {"true"},
+	"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-address-type": {"intranet"},
+	"yandex.cloud/load-balancer-type":                                    {"internal"},
+}
+
+var metadataHosts = []string{
+	"169.254.169.254",
+	"169.254.170.2",
+	"100.100.100.200",
+	"fd00:ec2::254",
+	"metadata",
+	"metadata.google.internal",
+	"metadata.azure.com",
+	"instance-data",
+}
+
+func detectServices(svcs []k8s.Service) []model.Finding {
+	var out []model.Finding
+	for _, s := range svcs {
+		ref := model.ResourceRef{Kind: "Service", Namespace: s.Metadata.Namespace, Name: s.Metadata.Name}
+		typ := strings.ToLower(s.Spec.Type)
+
+		if typ == "nodeport" {
+			ports := []string{}
+			for _, p := range s.Spec.Ports {
+				ports = append(ports, fmt.Sprintf("%d->%d/%s", p.Port, p.NodePort, strings.ToUpper(p.Protocol)))
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-NET-004",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Service типа NodePort",
+				Evidence:       fmt.Sprintf("type=NodePort ports=%v", ports),
+				Risk:           "NodePort расширяет поверхность атаки (порт на узлах)",
+				Recommendation: "Избегать NodePort, использовать Ingress/LoadBalancer с явным контролем доступа",
+			})
+		}
+
+		internalLB := typ == "loadbalancer" && isInternalLB(s.Metadata.Annotations)
+		if typ == "loadbalancer" {
+			ev := "type=LoadBalancer"
+			if addrs := lbAddresses(s); len(addrs) > 0 {
+				ev += fmt.Sprintf(" address=%v", addrs)
+			}
+			switch {
+			case internalLB:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-006",
+					Severity:       model.SeverityLow,
+					Resource:       ref,
+					Title:          "Service типа LoadBalancer (внутренний)",
+					Evidence:       ev + ", internal LB annotation set",
+					Risk:           "Сервис доступен из всей облачной сети (VPC), а не только из кластера",
+					Recommendation: "Ограничить loadBalancerSourceRanges подсетями, которым нужен доступ",
+				})
+			case len(s.Spec.LoadBalancerSourceRanges) == 0 || hasOpenCIDR(s.Spec.LoadBalancerSourceRanges):
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-006",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "Публичный LoadBalancer без ограничения источников",
+					Evidence:       fmt.Sprintf("%s loadBalancerSourceRanges=%v", ev, s.Spec.LoadBalancerSourceRanges),
+					Risk:           "Сервис доступен из интернета с любого адреса",
+					Recommendation: "Задать loadBalancerSourceRanges или сделать балансировщик внутренним",
+				})
+			default:
+				out = append(out, model.Finding{
+					CheckID:        "K8S-NET-006",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "Service типа LoadBalancer",
+					Evidence:       fmt.Sprintf("%s loadBalancerSourceRanges=%v", ev, s.Spec.LoadBalancerSourceRanges),
+					Risk:           "Возможна внешняя экспозиция сервиса",
+					Recommendation: "Проверить необходимость внешнего доступа и настроить TLS",
+				})
+			}
+		}
+
+		if len(s.Spec.ExternalIPs) > 0 {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SVC-001",
+				Severity:       model.SeverityHigh,
+				Resource:       ref,
+				Title:          "Service использует externalIPs",
+				Evidence:       fmt.Sprintf("spec.externalIPs=%v", s.Spec.ExternalIPs),
+				Risk:           "externalIPs позволяют перехватывать трафик к произвольным IP внутри кластера (MITM, CVE-2020-8554)",
+				Recommendation: "Не использовать externalIPs; запретить поле admission-политикой (DenyServiceExternalIPs)",
+			})
+		}
+
+		if typ == "externalname" {
+			target := strings.TrimSuffix(strings.ToLower(s.Spec.ExternalName), ".")
+			if isMetadataHost(target) {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-SVC-002",
+					Severity:       model.SeverityHigh,
+					Resource:       ref,
+					Title:          "ExternalName указывает на cloud metadata",
+					Evidence:       fmt.Sprintf("externalName=%q", s.Spec.ExternalName),
+					Risk:           "Через Ingress/прокси на этот Service можно получить облачные учетные данные узла",
+					Recommendation: "Удалить Service и блокировать egress к IMDS",
+				})
+			} else if isClusterInternalHost(target) {
+				out = append(out, model.Finding{
+					CheckID:        "K8S-SVC-002",
+					Severity:       model.SeverityMedium,
+					Resource:       ref,
+					Title:          "ExternalName указывает на внутренний адрес кластера",
+					Evidence:       fmt.Sprintf("externalName=%q", s.Spec.ExternalName),
+					Risk:           "Ingress-контроллер проксирует трафик в чужие namespace в обход NetworkPolicy (CVE-2021-25740)",
+					Recommendation: "Не использовать ExternalName для внутрикластерных адресов; ссылаться на Service своего namespace",
+				})
+			}
+		}
+
+		external := typ == "nodeport" || (typ == "loadbalancer" && !internalLB) || len(s.Spec.ExternalIPs) > 0
+		if !external {
+			continue
+		}
+
+		if (typ == "nodeport" || typ == "loadbalancer") && !strings.EqualFold(s.Spec.ExternalTrafficPolicy, "Local") {
+			policy := s.Spec.ExternalTrafficPolicy
+			if policy == "" {
+				policy = "Cluster"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SVC-003",
+				Severity:       model.SeverityLow,
+				Resource:       ref,
+				Title:          "externalTrafficPolicy=Cluster скрывает IP клиента",
+				Evidence:       fmt.Sprintf("type=%s externalTrafficPolicy=%s", s.Spec.Type, policy),
+				Risk:           "Под видит IP узла вместо клиента: ipBlock в NetworkPolicy и allowlist в приложении не работают",
+				Recommendation: "Использовать externalTrafficPolicy: Local, если фильтрация по IP источника важна",
+			})
+		}
+
+		for _, p := range s.Spec.Ports {
+			sp, ok := sensitivePorts[p.Port]
+			port := p.Port
+			if !ok && !p.TargetPort.IsString {
+				sp, ok = sensitivePorts[p.TargetPort.IntVal]
+				port = p.TargetPort.IntVal
+			}
+			if !ok {
+				continue
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SVC-004",
+				Severity:       sp.Severity,
+				Resource:       ref,
+				Title:          fmt.Sprintf("Наружу опубликован порт %s", sp.Service),
+				Evidence:       fmt.Sprintf("type=%s port=%d targetPort=%s nodePort=%d (%d: %s)", s.Spec.Type, p.Port, p.TargetPort.String(), p.NodePort, port, sp.Service),
+				Risk:           "Административные и data-порты часто не требуют аутентификации и дают прямой доступ к данным или узлу",
+				Recommendation: "Оставить сервис ClusterIP и предоставлять доступ через VPN/bastion",
+			})
+		}
+	}
+	return out
+}
+
+func isInternalLB(ann map[string]string) bool {
+	for k, vals := range internalLBAnnotations {
+		v, ok := ann[k]
+		if !ok {
+			continue
+		}
+		for _, want := range vals {
+			if strings.EqualFold(strings.TrimSpace(v), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasOpenCIDR(ranges []string) bool {
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "0.0.0.0/0" || r == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+func lbAddresses(s k8s.Service) []string {
+	var out []string
+	for _, in := range s.Status.LoadBalancer.Ingress {
+		if in.IP != "" {
+			out = append(out, in.IP)
+		}
+		if in.Hostname != "" {
+			out = append(out, in.Hostname)
+		}
+	}
+	return out
+}
+
+func isMetadataHost(h string) bool {
+	for _, m := range metadataHosts {
+		if h == m {
+			return true
+		}
+	}
+	return false
+}
+
+func isClusterInternalHost(h string) bool {
+	if h == "localhost" || h == "kubernetes" || strings.HasPrefix(h, "kubernetes.default") {
+		return true
+	}
+	if strings.HasSuffix(h, ".svc") || strings.Contains(h, ".svc.") || strings.HasSuffix(h, ".cluster.local") {
+		return true
+	}
+	if ip := net.ParseIP(h); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/cosign"
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+type signedImage struct {
+	ref    imageref.Ref
+	digest string
+	pods   []string
+}
+
+// DetectImageSignatures verifies cosign signatures of running images against the
+// configured keys (opt-in: v is nil unless keys are given).
+func DetectImageSignatures(pods []k8s.Pod, v *cosign.Verifier) []model.Finding {
+	if v == nil {
+		return nil
+	}
+	var out []model.Finding
+	images := map[string]*signedImage{}
+	// name:tag -> digest the pods actually run -> pods
+	tags := map[string]map[string][]string{}
+	tagRefs := map[string]imageref.Ref{}
+
+	for _, p := range pods {
+		pk := p.Metadata.Namespace + "/" + p.Metadata.Name
+		statuses := map[string]k8s.ContainerStatus{}
+		for _, st := range append(append([]k8s.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...) {
+			statuses[st.Name] = st
+		}
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			if c.Image == "" {
+				continue
+			}
+			ref := imageref.Parse(c.Image)
+			digest := imageref.DigestFromImageID(statuses[c.Name].ImageID)
+			if digest == "" {
+				digest = ref.Digest
+			}
+			if ref.Tag != "" && digest != "" {
+				tk := ref.Name() + ":" + ref.Tag
+				if tags[tk] == nil {
+					tags[tk] = map[string][]string{}
+					tagRefs[tk] = ref
+				}
+				tags[tk][digest] = append(tags[tk][digest], pk)
+			}
+			key := ref.String()
+			if digest != "" {
+				key = ref.Name() + "@" + digest
+			}
+			img, ok := images[key]
+			if !ok {
+				img = &signedImage{ref: ref, digest: digest}
+				images[key] = img
+			}
+			img.pods = append(img.pods, pk)
+		}
+	}
+
+	keys := make([]string, 0, len(images))
+	for k := range images {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		img := images[k]
+		pods := strings.Join(uniqStrings(img.pods), ",")
+		digest := img.digest
+		var err error
+		if digest == "" {
+			digest, err = v.Registry.Resolve(img.ref, img.ref.Tag)
+		}
+		var res cosign.Result
+		if err == nil {
+			res, err = v.Verify(img.ref, digest)
+		}
+		name := img.ref.Name() + "@" + digest
+		if err != nil {
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SIG-004",
+				Severity:       model.SeverityLow,
+				Resource:       model.ResourceRef{Kind: "Image", Name: img.ref.String()},
+				Title:          "Не удалось проверить подпись образа",
+				Evidence:       fmt.Sprintf("%v; pods: %s", err, pods),
+				Risk:           "Происхождение образа не подтверждено",
+				Recommendation: "Проверить доступность registry (или -signature-registry) из Pod'а аудита",
+			})
+			continue
+		}
+		switch res.Status {
+		case cosign.Unsigned:
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SIG-001",
+				Severity:       model.SeverityHigh,
+				Resource:       model.ResourceRef{Kind: "Image", Name: name},
+				Title:          "Запущен неподписанный образ",
+				Evidence:       fmt.Sprintf("no cosign signature (%s.sig); pods: %s", strings.Replace(digest, ":", "-", 1), pods),
+				Risk:           "Нельзя подтвердить, что образ собран доверенным конвейером, а не подложен в registry",
+				Recommendation: "Подписывать образы в CI (cosign sign --key) и требовать подпись admission-политикой",
+			})
+		case cosign.UnknownKey:
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SIG-002",
+				Severity:       model.SeverityHigh,
+				Resource:       model.ResourceRef{Kind: "Image", Name: name},
+				Title:          "Подпись образа не проверяется доверенными ключами",
+				Evidence:       fmt.Sprintf("%d signature(s), none valid for keys %s; pods: %s", res.Signatures, keyNames(v.Keys), pods),
+				Risk:           "Образ подписан неизвестным ключом: подпись не подтверждает доверенное происхождение",
+				Recommendation: "Выяснить происхождение образа; подписывать только ключами из доверенного набора",
+			})
+		case cosign.PayloadMismatch:
+			out = append(out, model.Finding{
+				CheckID:        "K8S-SIG-003",
+				Severity:       model.SeverityHigh,
+				Resource:       model.ResourceRef{Kind: "Image", Name: name},
+				Title:          "Подпись относится к другому digest",
+				Evidence:       fmt.Sprintf("signature by %s claims docker-manifest-digest=%s; pods: %s", res.Key, res.Claimed, pods),
+				Risk:           "Подпись скопирована с другого образа; содержимое запущенного образа не подписано",
+				Recommendation: "Проверить registry на подмену артефактов и переподписать образ",
+			})
+		}
+	}
+
+	tagKeys := make([]string, 0, len(tags))
+	for k := range tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, tk := range tagKeys {
+		ref := tagRefs[tk]
+		current, err := v.Registry.Resolve(ref, ref.Tag)
+		if err != nil {
+			continue
+		}
+		var parts []string
+		for d, ps := range tags[tk] {
+			if d != current {
+				parts = append(parts, fmt.Sprintf("%s: %s", shortDigest(d), strings.Join(uniqStrings(ps), ",")))
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		sort.Strings(parts)
+		out = append(out, model.Finding{
+			CheckID:        "K8S-SIG-003",
+			Severity:       model.SeverityMedium,
+			Resource:       model.ResourceRef{Kind: "Image", Name: tk},
+			Title:          "Тег в registry указывает на другой digest, чем запущенный",
+			Evidence:       fmt.Sprintf("registry %s -> %s; running %s", tk, shortDigest(current), strings.Join(parts, "; ")),
+			Risk:           "Тег перезаписан после запуска: пересоздание Pod'ов молча сменит содержимое образа",
+			Recommendation: "Закрепить образы по digest и выяснить, кто перезаписал тег",
+		})
+	}
+	return out
+}
+
+func keyNames(keys []cosign.PublicKey) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.Name)
+	}
+	return "[" + strings.Join(names, ",") + "]"
+}
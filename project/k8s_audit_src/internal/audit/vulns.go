@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/vulnreport"
+)
+
+// maxVulnEvidence limits how many CVEs are listed per finding.
+const maxVulnEvidence = 5
+
+type vulnWorkload struct {
+	ref      model.ResourceRef
+	report   *vulnreport.ImageReport
+	image    string
+	pods     []string
+	exposed  bool
+	critical []string
+}
+
+// DetectImageVulns attaches imported scan results to the workloads running the scanned images.
+// Severity is raised when the workload is exposed or can take over the node or cluster.
+func DetectImageVulns(pods []k8s.Pod, svcs []k8s.Service, ing []k8s.Ingress, saIndex map[string]k8s.ServiceAccount, e EffectiveRBAC, idx *vulnreport.Index) []model.Finding {
+	if idx == nil || len(idx.Reports) == 0 {
+		return nil
+	}
+	exposed := exposedPods(pods, svcs, ing)
+
+	// controller + report -> workload
+	byKey := map[string]*vulnWorkload{}
+	var keys []string
+	for _, p := range pods {
+		statuses := map[string]k8s.ContainerStatus{}
+		for _, st := range append(append([]k8s.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...) {
+			statuses[st.Name] = st
+		}
+		pk := p.Metadata.Namespace + "/" + p.Metadata.Name
+		owner := podController(p)
+		var critical []string
+		riskChecked := false
+
+		containers := append([]k8s.Container{}, p.Spec.InitContainers...)
+		containers = append(containers, p.Spec.Containers...)
+		for _, c := range containers {
+			if c.Image == "" {
+				continue
+			}
+			st := statuses[c.Name]
+			r := idx.Lookup(c.Image, imageref.DigestFromImageID(st.ImageID))
+			if r == nil || len(r.Vulns) == 0 {
+				continue
+			}
+			if !riskChecked {
+				critical, _, _ = podExposureRisks(p, saIndex, e)
+				riskChecked = true
+			}
+			key := owner.Kind + "/" + owner.Namespace + "/" + owner.Name + "|" + r.Source + "|" + r.Image
+			w, ok := byKey[key]
+			if !ok {
+				w = &vulnWorkload{ref: owner, report: r, image: c.Image}
+				byKey[key] = w
+				keys = append(keys, key)
+			}
+			w.pods = append(w.pods, pk)
+			if _, ok := exposed[pk]; ok {
+				w.exposed = true
+			}
+			w.critical = append(w.critical, critical...)
+		}
+	}
+	sort.Strings(keys)
+
+	var out []model.Finding
+	for _, k := range keys {
+		w := byKey[k]
+		base := w.report.MaxSeverity()
+		if base == "" {
+			continue
+		}
+		sev := base
+		var context []string
+		if w.exposed {
+			context = append(context, "internet-exposed")
+		}
+		if crit := uniqStrings(w.critical); len(crit) > 0 {
+			context = append(context, strings.Join(crit, ", "))
+		}
+		switch {
+		case w.exposed && len(w.critical) > 0:
+			sev = model.SeverityCritical
+		case w.exposed || len(w.critical) > 0:
+			sev = raiseSeverity(base)
+		}
+
+		ev := fmt.Sprintf("image=%q (%s, %s); %s; top: %s; pods: %s",
+			w.image, w.report.Format, w.report.Source, vulnCounts(w.report.Vulns),
+			topVulns(w.report.Vulns), strings.Join(uniqStrings(w.pods), ","))
+		if len(context) > 0 {
+			ev += "; context: " + strings.Join(context, "; ")
+		}
+		out = append(out, model.Finding{
+			CheckID:        "K8S-VULN-001",
+			Severity:       sev,
+			Resource:       w.ref,
+			Title:          "Запущен образ с известными уязвимостями",
+			Evidence:       truncateEvidence(ev, 1024),
+			Risk:           vulnRisk(w.exposed, len(w.critical) > 0),
+			Recommendation: "Обновить пакеты до исправленных версий и пересобрать образ; до этого снизить привилегии и публикацию workload",
+		})
+	}
+	return out
+}
+
+// podController resolves the top-level controller of a pod. ReplicaSets and Jobs created by
+// Deployments and CronJobs are mapped by stripping the generated suffix.
+func podController(p k8s.Pod) model.ResourceRef {
+	ns := p.Metadata.Namespace
+	for _, o := range p.Metadata.OwnerReferences {
+		if o.Controller == nil || !*o.Controller {
+			continue
+		}
+		switch o.Kind {
+		case "ReplicaSet":
+			if h := p.Metadata.Labels["pod-template-hash"]; h != "" && strings.HasSuffix(o.Name, "-"+h) {
+				return model.ResourceRef{Kind: "Deployment", Namespace: ns, Name: strings.TrimSuffix(o.Name, "-"+h)}
+			}
+		case "Job":
+			if i := strings.LastIndex(o.Name, "-"); i > 0 && isDigits(o.Name[i+1:]) {
+				return model.ResourceRef{Kind: "CronJob", Namespace: ns, Name: o.Name[:i]}
+			}
+		}
+		return model.ResourceRef{Kind: o.Kind, Namespace: ns, Name: o.Name}
+	}
+	return model.ResourceRef{Kind: "Pod", Namespace: ns, Name: p.Metadata.Name}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func raiseSeverity(s model.Severity) model.Severity {
+	switch s {
+	case model.SeverityLow:
+		return model.SeverityMedium
+	case model.SeverityMedium:
+		return model.SeverityHigh
+	}
+	return model.SeverityCritical
+}
+
+func vulnCounts(vs []vulnreport.Vuln) string {
+	counts := map[model.Severity]int{}
+	for _, v := range vs {
+		counts[v.Severity]++
+	}
+	var parts []string
+	for _, s := range []model.Severity{model.SeverityCritical, model.SeverityHigh, model.SeverityMedium, model.SeverityLow} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", s, counts[s]))
+		}
+	}
+	return fmt.Sprintf("%d vulns (%s)", len(vs), strings.Join(parts, " "))
+}
+
+// topVulns lists the first CVEs (reports are sorted by severity and CVSS).
+func topVulns(vs []vulnreport.Vuln) string {
+	var parts []string
+	for i, v := range vs {
+		if i == maxVulnEvidence {
+			parts = append(parts, fmt.Sprintf("+%d more", len(vs)-i))
+			break
+		}
+		s := fmt.Sprintf("%s %s %s", v.ID, v.Package, v.Installed)
+		if v.CVSS > 0 {
+			s += fmt.Sprintf(" cvss=%.1f", v.CVSS)
+		}
+		if v.Fixed != "" {
+			s += " fixed=" + v.Fixed
+		} else {
+			s += " no fix"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func vulnRisk(exposed, critical bool) string {
+	switch {
+	case exposed && critical:
+		return "Уязвимость во внешне доступном сервисе с привилегиями дает атакующему путь к захвату узла или кластера"
+	case exposed:
+		return "Уязвимость доступна для эксплуатации из внешней сети"
+	case critical:
+		return "Эксплуатация уязвимости в привилегированном workload позволяет выйти на узел или в kube-API"
+	}
+	return "Эксплуатация известных уязвимостей образа после получения доступа к workload"
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+	"example.com/k8s-audit/internal/vulnreport"
+)
+
+func TestDetectImageVulns(t *testing.T) {
+	running := "sha256:" + strings.Repeat("a", 64)
+	dir := t.TempDir()
+	reports := map[string]string{
+		"nginx.json": `{"ArtifactName": "nginx:1.25.3", "Metadata": {"RepoDigests": ["nginx@` + running + `"]},
+			"Results": [{"Vulnerabilities": [{"VulnerabilityID": "CVE-2023-44487", "PkgName": "libnghttp2-14", "InstalledVersion": "1.52.0-1", "Severity": "HIGH"}]}]}`,
+		"redis.json": `{"ArtifactName": "redis:7.2.3",
+			"Results": [{"Vulnerabilities": [{"VulnerabilityID": "CVE-2023-6129", "PkgName": "libssl3", "InstalledVersion": "3.1.4-r1", "Severity": "CRITICAL"}]}]}`,
+	}
+	for name, body := range reports {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idx, err := vulnreport.Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pod runs nginx under another tag; the report is matched by the running digest
+	pods := []k8s.Pod{{
+		Metadata: k8s.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Spec:     k8s.PodSpec{Containers: []k8s.Container{{Name: "web", Image: "nginx:stable"}}},
+		Status: k8s.PodStatus{ContainerStatuses: []k8s.ContainerStatus{
+			{Name: "web", Image: "docker.io/library/nginx:stable", ImageID: "docker.io/library/nginx@" + running},
+		}},
+	}}
+	got := DetectImageVulns(pods, nil, nil, nil, EffectiveRBAC{}, idx)
+	if len(got) != 1 {
+		t.Fatalf("got %d findings, want 1 (redis is not running): %+v", len(got), got)
+	}
+	f := got[0]
+	if f.CheckID != "K8S-VULN-001" || f.Severity != model.SeverityHigh || f.Resource.Name != "web-1" || !strings.Contains(f.Evidence, "CVE-2023-44487") {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+
+	if got := DetectImageVulns(nil, nil, nil, nil, EffectiveRBAC{}, idx); len(got) != 0 {
+		t.Errorf("no pods: got %+v", got)
+	}
+}
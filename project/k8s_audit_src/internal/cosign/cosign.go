@@ -0,0 +1,150 @@
+// Package cosign verifies cosign key-based image signatures stored in an OCI
+// registry under the sha256-<digest>.sig tag. Keyless (Fulcio/Rekor) signatures
+// are not supported: verification is fully offline apart from the registry.
+package cosign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/registry"
+)
+
+const signatureAnnotation = "dev.cosignproject.cosign/signature"
+
+type PublicKey struct {
+	Name string // file name, shown in evidence
+	Key  crypto.PublicKey
+}
+
+// LoadKeys reads PEM-encoded PKIX public keys (cosign.pub).
+func LoadKeys(paths []string) ([]PublicKey, error) {
+	var out []PublicKey
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM block", p)
+		}
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		out = append(out, PublicKey{Name: filepath.Base(p), Key: k})
+	}
+	return out, nil
+}
+
+type Status string
+
+const (
+	Verified        Status = "verified"
+	Unsigned        Status = "unsigned"
+	UnknownKey      Status = "unknown-key"
+	PayloadMismatch Status = "payload-mismatch"
+)
+
+type Result struct {
+	Status     Status
+	Signatures int
+	Key        string // key that verified the signature
+	Claimed    string // docker-manifest-digest of a mismatching payload
+}
+
+type Verifier struct {
+	Registry *registry.Client
+	Keys     []PublicKey
+}
+
+// simpleSigning is the cosign signature payload.
+type simpleSigning struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify checks the signatures attached to ref's repository for digest.
+func (v *Verifier) Verify(ref imageref.Ref, digest string) (Result, error) {
+	tag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	m, _, err := v.Registry.Manifest(ref, tag)
+	if errors.Is(err, registry.ErrNotFound) {
+		return Result{Status: Unsigned}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Status: UnknownKey}
+	for _, l := range m.Layers {
+		sigB64, ok := l.Annotations[signatureAnnotation]
+		if !ok {
+			continue
+		}
+		res.Signatures++
+		sig, err := base64.StdEncoding.DecodeString(sigB64)
+		if err != nil {
+			continue
+		}
+		payload, err := v.Registry.Blob(ref, l.Digest)
+		if err != nil {
+			return Result{}, err
+		}
+		key := v.match(payload, sig)
+		if key == "" {
+			continue
+		}
+		var ss simpleSigning
+		if err := json.Unmarshal(payload, &ss); err != nil {
+			continue
+		}
+		if got := ss.Critical.Image.DockerManifestDigest; got != digest {
+			res.Status, res.Key, res.Claimed = PayloadMismatch, key, got
+			continue
+		}
+		return Result{Status: Verified, Signatures: res.Signatures, Key: key}, nil
+	}
+	if res.Signatures == 0 {
+		res.Status = Unsigned
+	}
+	return res, nil
+}
+
+// match returns the name of the first key that verifies sig over payload.
+func (v *Verifier) match(payload, sig []byte) string {
+	sum := sha256.Sum256(payload)
+	for _, k := range v.Keys {
+		switch pub := k.Key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(pub, sum[:], sig) {
+				return k.Name
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig) == nil {
+				return k.Name
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(pub, payload, sig) {
+				return k.Name
+			}
+		}
+	}
+	return ""
+}
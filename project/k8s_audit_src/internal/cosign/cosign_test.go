@@ -0,0 +1,158 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/registry"
+)
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func payloadFor(digest string) []byte {
+	return []byte(`{"critical":{"identity":{"docker-reference":"registry.example/team/app"},"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`)
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, payload []byte) string {
+	t.Helper()
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// sigLayer is a payload blob with its base64 signature annotation.
+type sigLayer struct {
+	payload []byte
+	sig     string
+}
+
+// serveSignatures runs a plain-HTTP registry with the .sig manifests in sigs (tag -> layers).
+func serveSignatures(t *testing.T, sigs map[string][]sigLayer) *registry.Client {
+	t.Helper()
+	blobs := map[string][]byte{}
+	manifests := map[string][]byte{}
+	for tag, layers := range sigs {
+		var m registry.Manifest
+		m.MediaType = "application/vnd.oci.image.manifest.v1+json"
+		for _, l := range layers {
+			d := digestOf(l.payload)
+			blobs[d] = l.payload
+			m.Layers = append(m.Layers, registry.Descriptor{
+				MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+				Digest:      d,
+				Size:        int64(len(l.payload)),
+				Annotations: map[string]string{signatureAnnotation: l.sig},
+			})
+		}
+		b, _ := json.Marshal(m)
+		manifests[tag] = b
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/v2/team/app/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		kind, ref, _ := strings.Cut(rest, "/")
+		var body []byte
+		switch kind {
+		case "manifests":
+			body, ok = manifests[ref]
+		case "blobs":
+			body, ok = blobs[ref]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return registry.New(registry.Options{Mirror: strings.TrimPrefix(srv.URL, "http://"), PlainHTTP: true})
+}
+
+func TestVerify(t *testing.T) {
+	trusted, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys := []PublicKey{{Name: "cosign.pub", Key: &trusted.PublicKey}}
+
+	digest := digestOf([]byte("image manifest"))
+	tag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	good := payloadFor(digest)
+	stale := payloadFor(digestOf([]byte("previous manifest")))
+
+	tests := []struct {
+		name    string
+		layers  []sigLayer // nil: no .sig tag
+		want    Status
+		sigs    int
+		claimed string
+	}{
+		{"no sig tag", nil, Unsigned, 0, ""},
+		{"valid signature", []sigLayer{{good, sign(t, trusted, good)}}, Verified, 1, ""},
+		{"wrong key", []sigLayer{{good, sign(t, other, good)}}, UnknownKey, 1, ""},
+		{"wrong key then valid", []sigLayer{{stale, sign(t, other, stale)}, {good, sign(t, trusted, good)}}, Verified, 2, ""},
+		{"signature over another digest", []sigLayer{{stale, sign(t, trusted, stale)}}, PayloadMismatch, 1, digestOf([]byte("previous manifest"))},
+		{"signature not base64", []sigLayer{{good, "%%%"}}, UnknownKey, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sigs := map[string][]sigLayer{}
+			if tt.layers != nil {
+				sigs[tag] = tt.layers
+			}
+			v := &Verifier{Registry: serveSignatures(t, sigs), Keys: keys}
+			res, err := v.Verify(imageref.Parse("registry.example/team/app:v1"), digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.want || res.Signatures != tt.sigs || res.Claimed != tt.claimed {
+				t.Errorf("Verify() = %+v, want status %s, %d signatures, claimed %q", res, tt.want, tt.sigs, tt.claimed)
+			}
+			if tt.want == Verified && res.Key != "cosign.pub" {
+				t.Errorf("Key = %q, want cosign.pub", res.Key)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	good := filepath.Join(dir, "cosign.pub")
+	bad := filepath.Join(dir, "bad.pub")
+	os.WriteFile(good, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644)
+	os.WriteFile(bad, []byte("not a key"), 0o644)
+
+	keys, err := LoadKeys([]string{good})
+	if err != nil || len(keys) != 1 || keys[0].Name != "cosign.pub" || !key.PublicKey.Equal(keys[0].Key) {
+		t.Errorf("LoadKeys() = %v, %v", keys, err)
+	}
+	if _, err := LoadKeys([]string{good, bad}); err == nil || !strings.Contains(err.Error(), "no PEM block") {
+		t.Errorf("bad key: err = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package hostcheck
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"example.com/k8s-audit/internal/k8s"
+)
+
+// Minimal readers for the flat TOML of container runtimes and the kubelet YAML config.
+// Only what the checks need: no anchors, multi-line strings or inline tables.
+
+type tomlEntry struct {
+	section string
+	key     string
+	value   string
+}
+
+func parseTOML(text string) []tomlEntry {
+	var out []tomlEntry
+	section := ""
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && !strings.Contains(line, "=") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "[") {
+			// arrays may span lines
+			for !strings.Contains(value, "]") && i+1 < len(lines) {
+				i++
+				value += strings.TrimSpace(stripComment(lines[i]))
+			}
+			value = strings.ReplaceAll(strings.Join(strings.Fields(value), ""), ",]", "]")
+		}
+		out = append(out, tomlEntry{section: section, key: strings.Trim(strings.TrimSpace(key), `"`), value: unquote(value)})
+	}
+	return out
+}
+
+func stripComment(line string) string {
+	inQuote := false
+	for i, r := range line {
+		switch r {
+		case '"', '\'':
+			inQuote = !inQuote
+		case '#':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' && v[len(v)-1] == '"' || v[0] == '\'' && v[len(v)-1] == '\'') {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// kubeletConfig reads a KubeletConfiguration file with v1beta1 defaults for the fields checked.
+func (h host) kubeletConfig(p string) (*k8s.KubeletConfiguration, error) {
+	b, err := os.ReadFile(h.path(p))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(parseYAML(string(b)))
+	if err != nil {
+		return nil, err
+	}
+	anonymous, qps := false, int32(50)
+	kc := &k8s.KubeletConfiguration{EventRecordQPS: &qps}
+	kc.Authentication.Anonymous.Enabled = &anonymous
+	kc.Authorization.Mode = "Webhook"
+	if err := json.Unmarshal(raw, kc); err != nil {
+		return nil, err
+	}
+	return kc, nil
+}
+
+// parseYAML handles block mappings, block and flow sequences of scalars, and scalars.
+func parseYAML(text string) map[string]any {
+	type frame struct {
+		indent int // keys indented deeper than this belong to m
+		m      map[string]any
+	}
+	root := map[string]any{}
+	stack := []frame{{-1, root}}
+	var listParent map[string]any
+	listKey := ""
+
+	for _, line := range strings.Split(text, "\n") {
+		t := strings.TrimSpace(stripComment(line))
+		if t == "" || t == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if item, ok := strings.CutPrefix(t, "-"); ok && (item == "" || item[0] == ' ') {
+			if listParent != nil {
+				lst, _ := listParent[listKey].([]any)
+				listParent[listKey] = append(lst, yamlScalar(strings.TrimSpace(item)))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(t, ":")
+		if !ok {
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		m := stack[len(stack)-1].m
+		key = unquote(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "":
+			child := map[string]any{}
+			m[key] = child
+			stack = append(stack, frame{indent, child})
+			listParent, listKey = m, key
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			var lst []any
+			for _, v := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					lst = append(lst, yamlScalar(v))
+				}
+			}
+			m[key] = lst
+			listParent = nil
+		default:
+			m[key] = yamlScalar(value)
+			listParent = nil
+		}
+	}
+	return root
+}
+
+func yamlScalar(v string) any {
+	if len(v) > 0 && (v[0] == '"' || v[0] == '\'') {
+		return unquote(v)
+	}
+	switch v {
+	case "true", "True":
+		return true
+	case "false", "False":
+		return false
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package hostcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]any
+	}{
+		{
+			name: "nested maps and scalars",
+			in:   "a:\n  b:\n    c: true\n  d: 10\ne: text # comment\n",
+			want: map[string]any{"a": map[string]any{"b": map[string]any{"c": true}, "d": int64(10)}, "e": "text"},
+		},
+		{
+			name: "block list at key indentation",
+			in:   "list:\n- x\n- 'y'\nnext: 1\n",
+			want: map[string]any{"list": []any{"x", "y"}, "next": int64(1)},
+		},
+		{
+			name: "indented block list",
+			in:   "outer:\n  list:\n    - 1\n    - two\n  after: false\n",
+			want: map[string]any{"outer": map[string]any{"list": []any{int64(1), "two"}, "after": false}},
+		},
+		{
+			name: "flow list and quoted values",
+			in:   "list: [a, \"b\", 3]\nquoted: \"has # hash\"\nempty: []\n",
+			want: map[string]any{"list": []any{"a", "b", int64(3)}, "quoted": "has # hash", "empty": []any(nil)},
+		},
+		{
+			name: "document marker and dedent",
+			in:   "---\na:\n  b:\n    c: 1\nd: 2\n",
+			want: map[string]any{"a": map[string]any{"b": map[string]any{"c": int64(1)}}, "d": int64(2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseYAML(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubeletConfig(t *testing.T) {
+	h := host{root: "testdata/host"}
+
+	kc, err := h.kubeletConfig("/var/lib/kubelet/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := kc.Authentication.Anonymous.Enabled; a == nil || !*a {
+		t.Errorf("authentication.anonymous.enabled = %v, want true", a)
+	}
+	if w := kc.Authentication.Webhook.Enabled; w == nil || !*w {
+		t.Errorf("authentication.webhook.enabled = %v, want true", w)
+	}
+	if got := kc.Authentication.X509.ClientCAFile; got != "/etc/kubernetes/pki/ca.crt" {
+		t.Errorf("x509.clientCAFile = %q", got)
+	}
+	if kc.Authorization.Mode != "AlwaysAllow" || kc.ReadOnlyPort != 10255 || !kc.RotateCertificates || !kc.ServerTLSBootstrap {
+		t.Errorf("unexpected scalars: %+v", kc)
+	}
+	wantSuites := []string{"TLS_RSA_WITH_AES_128_CBC_SHA", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}
+	if !reflect.DeepEqual(kc.TLSCipherSuites, wantSuites) {
+		t.Errorf("tlsCipherSuites = %v, want %v", kc.TLSCipherSuites, wantSuites)
+	}
+	if kc.TLSMinVersion != "VersionTLS12" {
+		t.Errorf("tlsMinVersion = %q", kc.TLSMinVersion)
+	}
+	if kc.EventRecordQPS == nil || *kc.EventRecordQPS != 5 {
+		t.Errorf("eventRecordQPS = %v, want 5", kc.EventRecordQPS)
+	}
+
+	// fields absent from the file keep the v1beta1 defaults
+	kc, err = h.kubeletConfig("/var/lib/kubelet/minimal.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := kc.Authentication.Anonymous.Enabled; a == nil || *a {
+		t.Errorf("default anonymous.enabled = %v, want false", a)
+	}
+	if kc.Authorization.Mode != "Webhook" || kc.EventRecordQPS == nil || *kc.EventRecordQPS != 50 || !kc.SeccompDefault {
+		t.Errorf("unexpected defaults: %+v", kc)
+	}
+
+	if _, err := h.kubeletConfig("/var/lib/kubelet/missing.yaml"); err == nil {
+		t.Error("missing file: want error")
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	in := `version = 2
+[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = "pause:3.9#tag" # trailing comment
+  [plugins."io.containerd.grpc.v1.cri".registry]
+    "quoted.key" = 'single'
+[crio.runtime]
+default_capabilities = [
+	"CHOWN", # comment inside the array
+	"SYS_ADMIN",
+]
+empty = [ ]
+`
+	want := []tomlEntry{
+		{"", "version", "2"},
+		{`plugins."io.containerd.grpc.v1.cri"`, "sandbox_image", "pause:3.9#tag"},
+		{`plugins."io.containerd.grpc.v1.cri".registry`, "quoted.key", "single"},
+		{"crio.runtime", "default_capabilities", `["CHOWN","SYS_ADMIN"]`},
+		{"crio.runtime", "empty", "[]"},
+	}
+	if got := parseTOML(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTOML() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,336 @@
+// Package hostcheck runs host-level CIS checks against a node filesystem mounted
+// read-only at Root (e.g. /host in the node agent DaemonSet, or a fixture directory).
+package hostcheck
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/k8s-audit/internal/audit"
+	"example.com/k8s-audit/internal/k8s"
+	"example.com/k8s-audit/internal/model"
+)
+
+// Options configures a host check run.
+type Options struct {
+	Root string // host root; "" or "/" checks the local filesystem
+	Node string // node name findings are tagged with
+	// KubeletConfig is the kubelet config file path on the host (default /var/lib/kubelet/config.yaml).
+	KubeletConfig string
+}
+
+const defaultKubeletConfig = "/var/lib/kubelet/config.yaml"
+
+// Run performs all host checks. Missing paths are skipped: a worker has no
+// control-plane manifests and a node runs either containerd or CRI-O. The error
+// reports a kubelet config that could not be read; the other checks still run.
+func Run(opts Options) ([]model.Finding, error) {
+	h := host{root: opts.Root, res: model.ResourceRef{Kind: "Node", Name: opts.Node}}
+	if h.root == "" {
+		h.root = "/"
+	}
+	kubeletConfig := opts.KubeletConfig
+	if kubeletConfig == "" {
+		kubeletConfig = defaultKubeletConfig
+	}
+
+	var out []model.Finding
+	out = append(out, h.checkFiles(kubeletConfig)...)
+	kc, err := h.kubeletConfig(kubeletConfig)
+	if err == nil {
+		out = append(out, audit.DetectKubeletConfig(map[string]*k8s.KubeletConfiguration{opts.Node: kc})...)
+	} else {
+		err = fmt.Errorf("kubelet config %s: %w", kubeletConfig, err)
+	}
+	out = append(out, h.checkRuntimeConfig()...)
+	out = append(out, h.checkSysctls()...)
+	out = append(out, h.checkLSM()...)
+	return out, err
+}
+
+type host struct {
+	root string
+	res  model.ResourceRef
+}
+
+func (h host) path(p string) string {
+	return filepath.Join(h.root, filepath.FromSlash(p))
+}
+
+func (h host) read(p string) (string, error) {
+	b, err := os.ReadFile(h.path(p))
+	return strings.TrimSpace(string(b)), err
+}
+
+// File ownership and permissions (CIS 1.1.x, 4.1.x)
+
+type fileRule struct {
+	glob     string
+	maxMode  fs.FileMode
+	severity model.Severity
+	// serviceOwner allows a dedicated service user (e.g. etcd) instead of root
+	serviceOwner bool
+}
+
+var fileRules = []fileRule{
+	{"/etc/kubernetes/manifests/*.yaml", 0o600, model.SeverityMedium, false},
+	{"/etc/kubernetes/admin.conf", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/super-admin.conf", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/scheduler.conf", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/controller-manager.conf", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/kubelet.conf", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/pki", 0o755, model.SeverityMedium, false},
+	{"/etc/kubernetes/pki/*.crt", 0o644, model.SeverityMedium, false},
+	{"/etc/kubernetes/pki/*.key", 0o600, model.SeverityHigh, false},
+	{"/etc/kubernetes/pki/etcd/*.crt", 0o644, model.SeverityMedium, false},
+	{"/etc/kubernetes/pki/etcd/*.key", 0o600, model.SeverityHigh, false},
+	{"/var/lib/kubelet/pki/*.key", 0o600, model.SeverityHigh, false},
+	{"/var/lib/kubelet/pki/*.pem", 0o600, model.SeverityHigh, false},
+	{"/var/lib/etcd", 0o700, model.SeverityHigh, true},
+	{"/etc/cni/net.d/*", 0o644, model.SeverityMedium, false},
+	{"/etc/containerd/config.toml", 0o644, model.SeverityMedium, false},
+	{"/etc/crio/crio.conf", 0o644, model.SeverityMedium, false},
+	{"/run/containerd/containerd.sock", 0o660, model.SeverityHigh, false},
+	{"/var/run/crio/crio.sock", 0o660, model.SeverityHigh, false},
+}
+
+func (h host) checkFiles(kubeletConfig string) []model.Finding {
+	rules := append([]fileRule{{kubeletConfig, 0o600, model.SeverityMedium, false}}, fileRules...)
+	var out []model.Finding
+	for _, r := range rules {
+		matches, _ := filepath.Glob(h.path(r.glob))
+		sort.Strings(matches)
+		for _, m := range matches {
+			info, err := os.Lstat(m)
+			if err != nil || info.Mode()&fs.ModeSymlink != 0 {
+				continue
+			}
+			rel, _ := filepath.Rel(h.root, m)
+			rel = "/" + filepath.ToSlash(rel)
+			var issues []string
+			sev := r.severity
+			if extra := info.Mode().Perm() &^ r.maxMode; extra != 0 {
+				issues = append(issues, fmt.Sprintf("mode %04o (max %04o)", info.Mode().Perm(), r.maxMode))
+				// world access to a secret is worse than group access
+				if extra&0o007 == 0 {
+					sev = lowerSeverity(sev)
+				}
+			}
+			if uid, gid, ok := fileOwner(info); ok && (uid != 0 || gid != 0) && !r.serviceOwner {
+				issues = append(issues, fmt.Sprintf("owner %d:%d (want root:root)", uid, gid))
+			}
+			if len(issues) == 0 {
+				continue
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-HOST-001",
+				Severity:       sev,
+				Resource:       h.res,
+				Title:          "Небезопасные права на файл узла",
+				Evidence:       fmt.Sprintf("%s: %s", rel, strings.Join(issues, ", ")),
+				Risk:           "Непривилегированный пользователь или контейнер с доступом к ФС узла читает ключи и kubeconfig или подменяет конфигурацию компонентов",
+				Recommendation: fmt.Sprintf("chown root:root и chmod %04o (или строже)", r.maxMode),
+			})
+		}
+	}
+	return out
+}
+
+func lowerSeverity(s model.Severity) model.Severity {
+	switch s {
+	case model.SeverityCritical:
+		return model.SeverityHigh
+	case model.SeverityHigh:
+		return model.SeverityMedium
+	}
+	return model.SeverityLow
+}
+
+// Container runtime configuration
+
+type runtimeRule struct {
+	file     string
+	key      string
+	bad      func(v string) bool
+	severity model.Severity
+	why      string
+}
+
+var dangerousDefaultCaps = []string{"SYS_ADMIN", "NET_ADMIN", "SYS_PTRACE", "SYS_MODULE", "DAC_READ_SEARCH", "BPF", "SYS_RAWIO"}
+
+var runtimeRules = []runtimeRule{
+	{"/etc/containerd/config.toml", "disable_apparmor", isTrue, model.SeverityMedium, "AppArmor is not applied to containers"},
+	{"/etc/containerd/config.toml", "insecure_skip_verify", isTrue, model.SeverityMedium, "registry TLS certificates are not verified"},
+	{"/etc/crio/crio.conf", "apparmor_profile", func(v string) bool { return v == "unconfined" }, model.SeverityMedium, "containers run without AppArmor by default"},
+	{"/etc/crio/crio.conf", "insecure_registries", func(v string) bool { return v != "" && v != "[]" }, model.SeverityMedium, "images are pulled over plain HTTP or unverified TLS"},
+	{"/etc/crio/crio.conf", "default_capabilities", func(v string) bool {
+		for _, c := range dangerousDefaultCaps {
+			if strings.Contains(strings.ToUpper(v), c) {
+				return true
+			}
+		}
+		return false
+	}, model.SeverityHigh, "every container gets dangerous capabilities by default"},
+}
+
+func (h host) checkRuntimeConfig() []model.Finding {
+	var out []model.Finding
+	files := map[string][]tomlEntry{}
+	for _, r := range runtimeRules {
+		entries, ok := files[r.file]
+		if !ok {
+			entries = h.runtimeEntries(r.file)
+			files[r.file] = entries
+		}
+		for _, e := range entries {
+			if e.key != r.key || !r.bad(e.value) {
+				continue
+			}
+			loc := r.file
+			if e.section != "" {
+				loc += " [" + e.section + "]"
+			}
+			out = append(out, model.Finding{
+				CheckID:        "K8S-HOST-002",
+				Severity:       r.severity,
+				Resource:       h.res,
+				Title:          "Небезопасная конфигурация container runtime",
+				Evidence:       fmt.Sprintf("%s: %s = %s (%s)", loc, e.key, e.value, r.why),
+				Risk:           "Настройки runtime применяются ко всем контейнерам узла и ослабляют их изоляцию",
+				Recommendation: "Исправить параметр в конфигурации runtime и перезапустить его",
+			})
+		}
+	}
+	return out
+}
+
+// runtimeEntries reads a config file and its drop-in directory (e.g. crio.conf.d).
+func (h host) runtimeEntries(file string) []tomlEntry {
+	var out []tomlEntry
+	paths := []string{h.path(file)}
+	dropins, _ := filepath.Glob(h.path(file + ".d/*.conf"))
+	sort.Strings(dropins)
+	paths = append(paths, dropins...)
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		out = append(out, parseTOML(string(b))...)
+	}
+	return out
+}
+
+func isTrue(v string) bool { return v == "true" }
+
+// Kernel hardening sysctls (non-namespaced, so /proc/sys of any mount namespace shows the host value)
+
+type sysctlRule struct {
+	name     string
+	ok       func(n int) bool
+	want     string
+	severity model.Severity
+}
+
+var sysctlRules = []sysctlRule{
+	{"kernel.unprivileged_bpf_disabled", func(n int) bool { return n >= 1 }, ">= 1", model.SeverityMedium},
+	{"kernel.kptr_restrict", func(n int) bool { return n >= 1 }, ">= 1", model.SeverityMedium},
+	{"kernel.dmesg_restrict", func(n int) bool { return n == 1 }, "1", model.SeverityLow},
+	{"kernel.yama.ptrace_scope", func(n int) bool { return n >= 1 }, ">= 1", model.SeverityLow},
+	{"kernel.randomize_va_space", func(n int) bool { return n == 2 }, "2", model.SeverityMedium},
+	{"fs.protected_hardlinks", func(n int) bool { return n == 1 }, "1", model.SeverityLow},
+	{"fs.protected_symlinks", func(n int) bool { return n == 1 }, "1", model.SeverityLow},
+	{"fs.suid_dumpable", func(n int) bool { return n == 0 }, "0", model.SeverityLow},
+}
+
+func (h host) checkSysctls() []model.Finding {
+	var bad []string
+	sev := model.Severity("")
+	for _, r := range sysctlRules {
+		v, err := h.read("/proc/sys/" + strings.ReplaceAll(r.name, ".", "/"))
+		if err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || r.ok(n) {
+			continue
+		}
+		bad = append(bad, fmt.Sprintf("%s=%s (want %s)", r.name, v, r.want))
+		if model.SeverityRank(r.severity) > model.SeverityRank(sev) {
+			sev = r.severity
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	return []model.Finding{{
+		CheckID:        "K8S-HOST-003",
+		Severity:       sev,
+		Resource:       h.res,
+		Title:          "Не включены параметры защиты ядра",
+		Evidence:       strings.Join(bad, ", "),
+		Risk:           "Непривилегированный BPF, утечка адресов ядра и ptrace облегчают эксплуатацию уязвимостей ядра из контейнера",
+		Recommendation: "Задать параметры через /etc/sysctl.d на всех узлах",
+	}}
+}
+
+// Linux security modules
+
+func (h host) checkLSM() []model.Finding {
+	lsms := []string{}
+	if v, err := h.read("/sys/kernel/security/lsm"); err == nil {
+		lsms = strings.Split(v, ",")
+	}
+	apparmor := containsString(lsms, "apparmor")
+	if v, err := h.read("/sys/module/apparmor/parameters/enabled"); err == nil {
+		apparmor = v == "Y"
+	}
+	selinux := containsString(lsms, "selinux")
+	enforce, err := h.read("/sys/fs/selinux/enforce")
+	if err == nil {
+		selinux = true
+	}
+
+	switch {
+	case selinux && enforce == "0":
+		return []model.Finding{{
+			CheckID:        "K8S-HOST-004",
+			Severity:       model.SeverityMedium,
+			Resource:       h.res,
+			Title:          "SELinux в режиме permissive",
+			Evidence:       "/sys/fs/selinux/enforce=0",
+			Risk:           "Политика SELinux только журналирует нарушения и не мешает выходу из контейнера",
+			Recommendation: "Перевести SELinux в enforcing",
+		}}
+	case apparmor || selinux:
+		return nil
+	}
+	if len(lsms) == 0 {
+		if _, err := os.Stat(h.path("/sys/kernel")); err != nil {
+			return nil // /sys not visible under the root, nothing to conclude
+		}
+	}
+	return []model.Finding{{
+		CheckID:        "K8S-HOST-004",
+		Severity:       model.SeverityMedium,
+		Resource:       h.res,
+		Title:          "На узле не активен AppArmor или SELinux",
+		Evidence:       fmt.Sprintf("active LSMs: %s", strings.Join(lsms, ",")),
+		Risk:           "Без MAC-модуля профили AppArmor/SELinux из securityContext не применяются, и контейнеры ограничены только capabilities и seccomp",
+		Recommendation: "Включить AppArmor или SELinux в ядре и в container runtime",
+	}}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if strings.TrimSpace(v) == s {
+			return true
+		}
+	}
+	return false
+}
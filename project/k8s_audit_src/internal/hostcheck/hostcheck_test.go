@@ -0,0 +1,216 @@
+package hostcheck
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/model"
+)
+
+// copyRoot copies testdata/host to a temporary root so tests can change modes and files.
+func copyRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	err := filepath.WalkDir("testdata/host", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel("testdata/host", p)
+		dst := filepath.Join(root, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0o755)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, b, 0o600)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeFile(t *testing.T, root, p, content string) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func evidence(fs []model.Finding) string {
+	var parts []string
+	for _, f := range fs {
+		parts = append(parts, f.Evidence)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestCheckFiles(t *testing.T) {
+	tests := []struct {
+		path    string
+		mode    fs.FileMode
+		wantSev model.Severity // "" for no mode issue
+	}{
+		{"/etc/kubernetes/admin.conf", 0o600, ""},
+		{"/etc/kubernetes/admin.conf", 0o644, model.SeverityHigh},
+		{"/etc/kubernetes/admin.conf", 0o640, model.SeverityMedium}, // group only: lowered
+		{"/etc/kubernetes/pki/ca.key", 0o400, ""},
+		{"/etc/kubernetes/pki/ca.key", 0o604, model.SeverityHigh},
+		{"/etc/kubernetes/pki/ca.crt", 0o644, ""},
+		{"/etc/kubernetes/pki/ca.crt", 0o666, model.SeverityMedium},
+		{"/var/lib/kubelet/config.yaml", 0o660, model.SeverityLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.mode.String(), func(t *testing.T) {
+			root := copyRoot(t)
+			if err := os.Chmod(filepath.Join(root, filepath.FromSlash(tt.path)), tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			h := host{root: root, res: model.ResourceRef{Kind: "Node", Name: "n1"}}
+			var got *model.Finding
+			for _, f := range h.checkFiles(defaultKubeletConfig) {
+				// ownership depends on the user running the tests; only modes are checked
+				if strings.HasPrefix(f.Evidence, tt.path+":") && strings.Contains(f.Evidence, "mode ") {
+					got = &f
+				}
+			}
+			switch {
+			case tt.wantSev == "" && got != nil:
+				t.Errorf("unexpected finding: %s", got.Evidence)
+			case tt.wantSev != "" && got == nil:
+				t.Errorf("no finding, want %s", tt.wantSev)
+			case got != nil && (got.Severity != tt.wantSev || got.CheckID != "K8S-HOST-001" || got.Resource.Name != "n1"):
+				t.Errorf("got %s %s %v, want %s", got.CheckID, got.Severity, got.Resource, tt.wantSev)
+			}
+		})
+	}
+}
+
+func TestCheckRuntimeConfig(t *testing.T) {
+	h := host{root: "testdata/host"}
+	ev := evidence(h.checkRuntimeConfig())
+	tests := []struct {
+		fragment string
+		want     bool
+	}{
+		{`[plugins."io.containerd.grpc.v1.cri"]: disable_apparmor = true`, true},
+		{`registry.configs."registry.local".tls]: insecure_skip_verify = true`, true},
+		{`registry.configs."ghcr.io".tls]: insecure_skip_verify`, false},
+		{`default_capabilities = ["CHOWN","SYS_ADMIN"]`, true}, // from the drop-in
+		{`default_capabilities = ["CHOWN","KILL"]`, false},
+		{`insecure_registries = ["registry.local:5000"]`, true},
+		{`insecure_registries = []`, false},
+		{`apparmor_profile`, false},
+	}
+	for _, tt := range tests {
+		if got := strings.Contains(ev, tt.fragment); got != tt.want {
+			t.Errorf("evidence contains %q = %v, want %v\nevidence:\n%s", tt.fragment, got, tt.want, ev)
+		}
+	}
+}
+
+func TestCheckSysctls(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     map[string]string
+		want    []string
+		wantSev model.Severity
+	}{
+		{"fixture", nil, []string{"kernel.kptr_restrict=0"}, model.SeverityMedium},
+		{"hardened", map[string]string{"kernel/kptr_restrict": "1"}, nil, ""},
+		{"low only", map[string]string{"kernel/kptr_restrict": "2", "fs/suid_dumpable": "2"}, []string{"fs.suid_dumpable=2"}, model.SeverityLow},
+		{"garbage ignored", map[string]string{"kernel/kptr_restrict": "x"}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := copyRoot(t)
+			for k, v := range tt.set {
+				writeFile(t, root, "/proc/sys/"+k, v+"\n")
+			}
+			got := host{root: root}.checkSysctls()
+			if len(tt.want) == 0 {
+				if len(got) != 0 {
+					t.Errorf("unexpected finding: %s", evidence(got))
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Severity != tt.wantSev {
+				t.Fatalf("got %v, want one %s finding", got, tt.wantSev)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got[0].Evidence, w) {
+					t.Errorf("evidence %q does not contain %q", got[0].Evidence, w)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLSM(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // evidence fragment, "" for no finding
+	}{
+		{"apparmor via lsm list", map[string]string{"/sys/kernel/security/lsm": "capability,apparmor"}, ""},
+		{"apparmor module disabled", map[string]string{"/sys/kernel/security/lsm": "capability,apparmor", "/sys/module/apparmor/parameters/enabled": "N"}, "active LSMs: capability,apparmor"},
+		{"selinux enforcing", map[string]string{"/sys/kernel/security/lsm": "capability,selinux", "/sys/fs/selinux/enforce": "1"}, ""},
+		{"selinux permissive", map[string]string{"/sys/fs/selinux/enforce": "0"}, "/sys/fs/selinux/enforce=0"},
+		{"no mac module", map[string]string{"/sys/kernel/security/lsm": "lockdown,capability,yama"}, "active LSMs: lockdown,capability,yama"},
+		{"sys not mounted", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for p, v := range tt.files {
+				writeFile(t, root, p, v+"\n")
+			}
+			got := host{root: root}.checkLSM()
+			switch {
+			case tt.want == "" && len(got) > 0:
+				t.Errorf("unexpected finding: %s", evidence(got))
+			case tt.want != "" && (len(got) != 1 || !strings.Contains(got[0].Evidence, tt.want)):
+				t.Errorf("got %q, want evidence with %q", evidence(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	findings, err := Run(Options{Root: "testdata/host", Node: "worker-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, f := range findings {
+		ids[f.CheckID] = true
+		if f.Resource.Kind != "Node" || f.Resource.Name != "worker-1" {
+			t.Errorf("%s: resource %v, want Node/worker-1", f.CheckID, f.Resource)
+		}
+	}
+	for _, id := range []string{"K8S-HOST-002", "K8S-HOST-003", "K8S-KUBELET-001", "K8S-KUBELET-003", "K8S-KUBELET-009"} {
+		if !ids[id] {
+			t.Errorf("missing %s", id)
+		}
+	}
+	if ids["K8S-HOST-004"] {
+		t.Error("K8S-HOST-004 reported although AppArmor is enabled")
+	}
+
+	findings, err = Run(Options{Root: "testdata/host", Node: "worker-1", KubeletConfig: "/etc/kubernetes/kubelet-missing.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "kubelet-missing.yaml") {
+		t.Errorf("missing kubelet config: err = %v", err)
+	}
+	if len(findings) == 0 {
+		t.Error("other checks must still run when the kubelet config is missing")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package hostcheck
+
+import "io/fs"
+
+func fileOwner(fs.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,74 @@
+package imageref
+
+import "strings"
+
+const DefaultRegistry = "docker.io"
+
+// Ref is a parsed container image reference: registry/repository[:tag][@digest].
+type Ref struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Parse normalises an image reference the way the container runtime does
+// (docker.io default registry, library/ prefix for official images).
+func Parse(s string) Ref {
+	var r Ref
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "@"); i >= 0 {
+		r.Digest = s[i+1:]
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, ":"); i >= 0 && !strings.Contains(s[i+1:], "/") {
+		r.Tag = s[i+1:]
+		s = s[:i]
+	}
+	first, rest, found := strings.Cut(s, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		r.Registry = first
+		r.Repository = rest
+	} else {
+		r.Registry = DefaultRegistry
+		r.Repository = s
+	}
+	if r.Registry == "index.docker.io" || r.Registry == "registry-1.docker.io" {
+		r.Registry = DefaultRegistry
+	}
+	if r.Registry == DefaultRegistry && !strings.Contains(r.Repository, "/") {
+		r.Repository = "library/" + r.Repository
+	}
+	return r
+}
+
+// Name is registry/repository without tag or digest.
+func (r Ref) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+func (r Ref) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Mutable reports whether the reference can resolve to different content over time.
+func (r Ref) Mutable() bool {
+	return r.Digest == ""
+}
+
+// DigestFromImageID extracts the manifest digest from a containerStatuses[].imageID
+// (docker-pullable://repo@sha256:... or repo@sha256:...). A bare sha256:... is the
+// local image config ID, not a manifest digest, and yields "".
+func DigestFromImageID(id string) string {
+	if i := strings.LastIndex(id, "@"); i >= 0 {
+		return id[i+1:]
+	}
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -75,11 +76,55 @@ func (c *Client) doGET(path string) ([]byte, error) {
 	defer resp.Body.Close()
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("GET %s: status %d: %s", path, resp.StatusCode, truncate(string(body), 300))
+		return nil, &APIError{Path: path, StatusCode: resp.StatusCode, Body: truncate(string(body), 300)}
 	}
 	return body, nil
 }
 
+// APIError is a non-2xx response from the API server.
+type APIError struct {
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GET %s: status %d: %s", e.Path, e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is a 404, e.g. an API group or CRD that is not installed.
+func IsNotFound(err error) bool {
+	var ae *APIError
+	return errors.As(err, &ae) && ae.StatusCode == http.StatusNotFound
+}
+
+// PreferredVersion returns the first of want served by the API group, or "" if none is.
+func (c *Client) PreferredVersion(group string, want ...string) (string, error) {
+	b, err := c.doGET("/apis/" + group)
+	if err != nil {
+		if IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var g struct {
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+	}
+	if err := json.Unmarshal(b, &g); err != nil {
+		return "", err
+	}
+	for _, w := range want {
+		for _, v := range g.Versions {
+			if v.Version == w {
+				return w, nil
+			}
+		}
+	}
+	return "", nil
+}
+
 func (c *Client) ServerVersion() string {
 	b, err := c.doGET("/version")
 	if err != nil {
@@ -149,6 +194,21 @@ func (c *Client) ListPodsAll() ([]Pod, error) {
 	})
 }
 
+// ListPods lists pods in one namespace matching a label selector.
+func (c *Client) ListPods(namespace, selector string) ([]Pod, error) {
+	p := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods?labelSelector=" + url.QueryEscape(selector)
+	return listAll[Pod](c, p, func(b []byte) ([]Pod, string, error) {
+		var lst PodList
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+}
+
+// PodLog returns the current log of a single-container pod.
+func (c *Client) PodLog(namespace, name string) ([]byte, error) {
+	return c.doGET("/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(name) + "/log")
+}
+
 func (c *Client) ListRolesAll() ([]Role, error) {
 	return listAll[Role](c, "/apis/rbac.authorization.k8s.io/v1/roles", func(b []byte) ([]Role, string, error) {
 		var lst RoleList
@@ -189,6 +249,38 @@ func (c *Client) ListNetworkPoliciesAll() ([]NetworkPolicy, error) {
 	})
 }
 
+func (c *Client) ListSecretsAll() ([]Secret, error) {
+	return listAll[Secret](c, "/api/v1/secrets", func(b []byte) ([]Secret, string, error) {
+		var lst SecretList
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+}
+
+func (c *Client) ListConfigMapsAll() ([]ConfigMap, error) {
+	return listAll[ConfigMap](c, "/api/v1/configmaps", func(b []byte) ([]ConfigMap, string, error) {
+		var lst ConfigMapList
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+}
+
+func (c *Client) ListLimitRangesAll() ([]LimitRange, error) {
+	return listAll[LimitRange](c, "/api/v1/limitranges", func(b []byte) ([]LimitRange, string, error) {
+		var lst LimitRangeList
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+}
+
+func (c *Client) ListResourceQuotasAll() ([]ResourceQuota, error) {
+	return listAll[ResourceQuota](c, "/api/v1/resourcequotas", func(b []byte) ([]ResourceQuota, string, error) {
+		var lst ResourceQuotaList
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+}
+
 func (c *Client) ListServicesAll() ([]Service, error) {
 	return listAll[Service](c, "/api/v1/services", func(b []byte) ([]Service, string, error) {
 		var lst ServiceList
@@ -213,6 +305,139 @@ func (c *Client) ListNodes() ([]Node, error) {
 	})
 }
 
+// KubeletConfig reads the running kubelet configuration through the API server node proxy.
+func (c *Client) KubeletConfig(node string) (*KubeletConfiguration, error) {
+	b, err := c.doGET("/api/v1/nodes/" + url.PathEscape(node) + "/proxy/configz")
+	if err != nil {
+		return nil, err
+	}
+	var v struct {
+		KubeletConfig *KubeletConfiguration `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if v.KubeletConfig == nil {
+		return nil, fmt.Errorf("node %s: configz has no kubeletconfig", node)
+	}
+	return v.KubeletConfig, nil
+}
+
+// CRD-backed resources.
+
+type itemList[T any] struct {
+	Items    []T      `json:"items"`
+	Metadata ListMeta `json:"metadata"`
+}
+
+// listOptional lists an optional API resource; a missing group or resource yields nil, nil.
+func listOptional[T any](c *Client, group, resource string, versions ...string) ([]T, error) {
+	v, err := c.PreferredVersion(group, versions...)
+	if err != nil || v == "" {
+		return nil, err
+	}
+	items, err := listAll[T](c, "/apis/"+group+"/"+v+"/"+resource, func(b []byte) ([]T, string, error) {
+		var lst itemList[T]
+		err := json.Unmarshal(b, &lst)
+		return lst.Items, lst.Metadata.Continue, err
+	})
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	return items, err
+}
+
+const gatewayGroup = "gateway.networking.k8s.io"
+
+func (c *Client) ListGateways() ([]Gateway, error) {
+	return listOptional[Gateway](c, gatewayGroup, "gateways", "v1", "v1beta1")
+}
+
+func (c *Client) ListHTTPRoutes() ([]HTTPRoute, error) {
+	return listOptional[HTTPRoute](c, gatewayGroup, "httproutes", "v1", "v1beta1")
+}
+
+func (c *Client) ListTLSRoutes() ([]TLSRoute, error) {
+	return listOptional[TLSRoute](c, gatewayGroup, "tlsroutes", "v1alpha3", "v1alpha2")
+}
+
+func (c *Client) ListReferenceGrants() ([]ReferenceGrant, error) {
+	return listOptional[ReferenceGrant](c, gatewayGroup, "referencegrants", "v1", "v1beta1", "v1alpha2")
+}
+
+func (c *Client) ListCiliumNetworkPolicies() ([]CiliumNetworkPolicy, error) {
+	return listOptional[CiliumNetworkPolicy](c, "cilium.io", "ciliumnetworkpolicies", "v2")
+}
+
+func (c *Client) ListCiliumClusterwideNetworkPolicies() ([]CiliumNetworkPolicy, error) {
+	return listOptional[CiliumNetworkPolicy](c, "cilium.io", "ciliumclusterwidenetworkpolicies", "v2")
+}
+
+func (c *Client) ListCalicoNetworkPolicies() ([]CalicoNetworkPolicy, error) {
+	return listCalico(c, "networkpolicies")
+}
+
+func (c *Client) ListCalicoGlobalNetworkPolicies() ([]CalicoNetworkPolicy, error) {
+	return listCalico(c, "globalnetworkpolicies")
+}
+
+// listCalico prefers the Calico API server (projectcalico.org/v3) and falls back to the raw CRDs.
+func listCalico(c *Client, resource string) ([]CalicoNetworkPolicy, error) {
+	v, err := c.PreferredVersion("projectcalico.org", "v3")
+	if err != nil {
+		return nil, err
+	}
+	if v != "" {
+		return listOptional[CalicoNetworkPolicy](c, "projectcalico.org", resource, v)
+	}
+	return listOptional[CalicoNetworkPolicy](c, "crd.projectcalico.org", resource, "v1")
+}
+
+const (
+	istioSecurityGroup = "security.istio.io"
+	linkerdPolicyGroup = "policy.linkerd.io"
+)
+
+// IstioInstalled reports whether the Istio security API is served.
+func (c *Client) IstioInstalled() bool {
+	v, err := c.PreferredVersion(istioSecurityGroup, "v1", "v1beta1")
+	return err == nil && v != ""
+}
+
+// LinkerdInstalled reports whether the Linkerd policy API is served.
+func (c *Client) LinkerdInstalled() bool {
+	v, err := c.PreferredVersion(linkerdPolicyGroup, "v1beta3", "v1beta2", "v1beta1", "v1alpha1")
+	return err == nil && v != ""
+}
+
+func (c *Client) ListPeerAuthentications() ([]PeerAuthentication, error) {
+	return listOptional[PeerAuthentication](c, istioSecurityGroup, "peerauthentications", "v1", "v1beta1")
+}
+
+func (c *Client) ListIstioAuthorizationPolicies() ([]IstioAuthorizationPolicy, error) {
+	return listOptional[IstioAuthorizationPolicy](c, istioSecurityGroup, "authorizationpolicies", "v1", "v1beta1")
+}
+
+func (c *Client) ListLinkerdServers() ([]LinkerdServer, error) {
+	return listOptional[LinkerdServer](c, linkerdPolicyGroup, "servers", "v1beta3", "v1beta2", "v1beta1")
+}
+
+func (c *Client) ListLinkerdAuthorizationPolicies() ([]LinkerdAuthorizationPolicy, error) {
+	return listOptional[LinkerdAuthorizationPolicy](c, linkerdPolicyGroup, "authorizationpolicies", "v1alpha1")
+}
+
+func (c *Client) ListLinkerdServerAuthorizations() ([]LinkerdServerAuthorization, error) {
+	return listOptional[LinkerdServerAuthorization](c, linkerdPolicyGroup, "serverauthorizations", "v1beta1")
+}
+
+func (c *Client) ListLinkerdNetworkAuthentications() ([]LinkerdNetworkAuthentication, error) {
+	return listOptional[LinkerdNetworkAuthentication](c, linkerdPolicyGroup, "networkauthentications", "v1alpha1")
+}
+
+func (c *Client) ListLinkerdMeshTLSAuthentications() ([]LinkerdMeshTLSAuthentication, error) {
+	return listOptional[LinkerdMeshTLSAuthentication](c, linkerdPolicyGroup, "meshtlsauthentications", "v1alpha1")
+}
+
 func truncate(s string, n int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	if len(s) <= n {
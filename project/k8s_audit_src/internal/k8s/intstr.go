@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// IntOrString mirrors k8s.io/apimachinery intstr.IntOrString.
+type IntOrString struct {
+	IntVal   int32
+	StrVal   string
+	IsString bool
+}
+
+func (v *IntOrString) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		v.IsString = true
+		return json.Unmarshal(b, &v.StrVal)
+	}
+	return json.Unmarshal(b, &v.IntVal)
+}
+
+func (v IntOrString) MarshalJSON() ([]byte, error) {
+	if v.IsString {
+		return json.Marshal(v.StrVal)
+	}
+	return json.Marshal(v.IntVal)
+}
+
+func (v IntOrString) String() string {
+	if v.IsString {
+		return v.StrVal
+	}
+	return strconv.Itoa(int(v.IntVal))
+}
@@ -3,9 +3,18 @@ package k8s
 // Minimal Kubernetes types (only fields used by audit).
 
 type ObjectMeta struct {
-	Name      string            `json:"name"`
-	Namespace string            `json:"namespace,omitempty"`
-	Labels    map[string]string `json:"labels,omitempty"`
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty"`
+}
+
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller *bool  `json:"controller,omitempty"`
 }
 
 type ListMeta struct {
@@ -26,8 +35,14 @@ type NamespaceList struct {
 // ServiceAccount
 
 type ServiceAccount struct {
-	Metadata                     ObjectMeta `json:"metadata"`
-	AutomountServiceAccountToken *bool      `json:"automountServiceAccountToken,omitempty"`
+	Metadata                     ObjectMeta             `json:"metadata"`
+	AutomountServiceAccountToken *bool                  `json:"automountServiceAccountToken,omitempty"`
+	Secrets                      []LocalObjectReference `json:"secrets,omitempty"`
+	ImagePullSecrets             []LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+type LocalObjectReference struct {
+	Name string `json:"name"`
 }
 
 type ServiceAccountList struct {
@@ -45,36 +60,139 @@ type PodList struct {
 type Pod struct {
 	Metadata ObjectMeta `json:"metadata"`
 	Spec     PodSpec    `json:"spec"`
+	Status   PodStatus  `json:"status,omitempty"`
+}
+
+type PodStatus struct {
+	Phase                 string            `json:"phase,omitempty"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
+}
+
+type ContainerStatus struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	ImageID string `json:"imageID"`
 }
 
 type PodSpec struct {
-	ServiceAccountName           string              `json:"serviceAccountName,omitempty"`
-	AutomountServiceAccountToken *bool               `json:"automountServiceAccountToken,omitempty"`
-	HostNetwork                  bool                `json:"hostNetwork,omitempty"`
-	HostPID                      bool                `json:"hostPID,omitempty"`
-	HostIPC                      bool                `json:"hostIPC,omitempty"`
-	SecurityContext              *PodSecurityContext `json:"securityContext,omitempty"`
-	Containers                   []Container         `json:"containers"`
-	InitContainers               []Container         `json:"initContainers,omitempty"`
-	Volumes                      []Volume            `json:"volumes,omitempty"`
+	ServiceAccountName           string                 `json:"serviceAccountName,omitempty"`
+	AutomountServiceAccountToken *bool                  `json:"automountServiceAccountToken,omitempty"`
+	HostNetwork                  bool                   `json:"hostNetwork,omitempty"`
+	HostPID                      bool                   `json:"hostPID,omitempty"`
+	HostIPC                      bool                   `json:"hostIPC,omitempty"`
+	ShareProcessNamespace        *bool                  `json:"shareProcessNamespace,omitempty"`
+	HostUsers                    *bool                  `json:"hostUsers,omitempty"`
+	SecurityContext              *PodSecurityContext    `json:"securityContext,omitempty"`
+	Containers                   []Container            `json:"containers"`
+	InitContainers               []Container            `json:"initContainers,omitempty"`
+	Volumes                      []Volume               `json:"volumes,omitempty"`
+	ImagePullSecrets             []LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	NodeName                     string                 `json:"nodeName,omitempty"`
+	NodeSelector                 map[string]string      `json:"nodeSelector,omitempty"`
+	Affinity                     *Affinity              `json:"affinity,omitempty"`
+	Tolerations                  []Toleration           `json:"tolerations,omitempty"`
+}
+
+type Toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+type Affinity struct {
+	NodeAffinity *NodeAffinity `json:"nodeAffinity,omitempty"`
+}
+
+type NodeAffinity struct {
+	RequiredDuringSchedulingIgnoredDuringExecution  *NodeSelector             `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	PreferredDuringSchedulingIgnoredDuringExecution []PreferredSchedulingTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+type NodeSelector struct {
+	NodeSelectorTerms []NodeSelectorTerm `json:"nodeSelectorTerms"`
+}
+
+type NodeSelectorTerm struct {
+	MatchExpressions []NodeSelectorRequirement `json:"matchExpressions,omitempty"`
+	MatchFields      []NodeSelectorRequirement `json:"matchFields,omitempty"`
+}
+
+type NodeSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+type PreferredSchedulingTerm struct {
+	Weight     int32            `json:"weight"`
+	Preference NodeSelectorTerm `json:"preference"`
 }
 
 type PodSecurityContext struct {
-	RunAsUser      *int64          `json:"runAsUser,omitempty"`
-	RunAsNonRoot   *bool           `json:"runAsNonRoot,omitempty"`
-	SeccompProfile *SeccompProfile `json:"seccompProfile,omitempty"`
+	RunAsUser       *int64                         `json:"runAsUser,omitempty"`
+	RunAsNonRoot    *bool                          `json:"runAsNonRoot,omitempty"`
+	SeccompProfile  *SeccompProfile                `json:"seccompProfile,omitempty"`
+	AppArmorProfile *AppArmorProfile               `json:"appArmorProfile,omitempty"`
+	SELinuxOptions  *SELinuxOptions                `json:"seLinuxOptions,omitempty"`
+	WindowsOptions  *WindowsSecurityContextOptions `json:"windowsOptions,omitempty"`
+	Sysctls         []Sysctl                       `json:"sysctls,omitempty"`
 }
 
 type SeccompProfile struct {
 	Type string `json:"type"`
 }
 
+type AppArmorProfile struct {
+	Type             string `json:"type"`
+	LocalhostProfile string `json:"localhostProfile,omitempty"`
+}
+
+type SELinuxOptions struct {
+	User  string `json:"user,omitempty"`
+	Role  string `json:"role,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Level string `json:"level,omitempty"`
+}
+
+type WindowsSecurityContextOptions struct {
+	HostProcess *bool `json:"hostProcess,omitempty"`
+}
+
+type Sysctl struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type Container struct {
-	Name            string           `json:"name"`
-	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
-	Env             []EnvVar         `json:"env,omitempty"`
-	EnvFrom         []EnvFromSource  `json:"envFrom,omitempty"`
-	VolumeMounts    []VolumeMount    `json:"volumeMounts,omitempty"`
+	Name            string               `json:"name"`
+	Image           string               `json:"image,omitempty"`
+	ImagePullPolicy string               `json:"imagePullPolicy,omitempty"`
+	Ports           []ContainerPort      `json:"ports,omitempty"`
+	Command         []string             `json:"command,omitempty"`
+	Args            []string             `json:"args,omitempty"`
+	SecurityContext *SecurityContext     `json:"securityContext,omitempty"`
+	Resources       ResourceRequirements `json:"resources,omitempty"`
+	Env             []EnvVar             `json:"env,omitempty"`
+	EnvFrom         []EnvFromSource      `json:"envFrom,omitempty"`
+	VolumeMounts    []VolumeMount        `json:"volumeMounts,omitempty"`
+}
+
+// ResourceList maps a resource name (cpu, memory, ephemeral-storage, count/pods, ...) to a quantity.
+type ResourceList map[string]string
+
+type ResourceRequirements struct {
+	Limits   ResourceList `json:"limits,omitempty"`
+	Requests ResourceList `json:"requests,omitempty"`
+}
+
+type ContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	HostPort      int32  `json:"hostPort,omitempty"`
+	HostIP        string `json:"hostIP,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
 }
 
 type EnvVar struct {
@@ -84,7 +202,13 @@ type EnvVar struct {
 }
 
 type EnvVarSource struct {
-	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+	SecretKeyRef    *SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+type ConfigMapKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 type SecretKeySelector struct {
@@ -93,7 +217,12 @@ type SecretKeySelector struct {
 }
 
 type EnvFromSource struct {
-	SecretRef *SecretEnvSource `json:"secretRef,omitempty"`
+	SecretRef    *SecretEnvSource    `json:"secretRef,omitempty"`
+	ConfigMapRef *ConfigMapEnvSource `json:"configMapRef,omitempty"`
+}
+
+type ConfigMapEnvSource struct {
+	Name string `json:"name"`
 }
 
 type SecretEnvSource struct {
@@ -101,19 +230,25 @@ type SecretEnvSource struct {
 }
 
 type VolumeMount struct {
-	Name      string `json:"name"`
-	MountPath string `json:"mountPath"`
-	ReadOnly  bool   `json:"readOnly,omitempty"`
+	Name             string `json:"name"`
+	MountPath        string `json:"mountPath"`
+	ReadOnly         bool   `json:"readOnly,omitempty"`
+	SubPath          string `json:"subPath,omitempty"`
+	MountPropagation string `json:"mountPropagation,omitempty"`
 }
 
 type SecurityContext struct {
-	Privileged               *bool           `json:"privileged,omitempty"`
-	AllowPrivilegeEscalation *bool           `json:"allowPrivilegeEscalation,omitempty"`
-	RunAsUser                *int64          `json:"runAsUser,omitempty"`
-	RunAsNonRoot             *bool           `json:"runAsNonRoot,omitempty"`
-	ReadOnlyRootFilesystem   *bool           `json:"readOnlyRootFilesystem,omitempty"`
-	Capabilities             *Capabilities   `json:"capabilities,omitempty"`
-	SeccompProfile           *SeccompProfile `json:"seccompProfile,omitempty"`
+	Privileged               *bool                          `json:"privileged,omitempty"`
+	AllowPrivilegeEscalation *bool                          `json:"allowPrivilegeEscalation,omitempty"`
+	RunAsUser                *int64                         `json:"runAsUser,omitempty"`
+	RunAsNonRoot             *bool                          `json:"runAsNonRoot,omitempty"`
+	ReadOnlyRootFilesystem   *bool                          `json:"readOnlyRootFilesystem,omitempty"`
+	Capabilities             *Capabilities                  `json:"capabilities,omitempty"`
+	SeccompProfile           *SeccompProfile                `json:"seccompProfile,omitempty"`
+	AppArmorProfile          *AppArmorProfile               `json:"appArmorProfile,omitempty"`
+	SELinuxOptions           *SELinuxOptions                `json:"seLinuxOptions,omitempty"`
+	WindowsOptions           *WindowsSecurityContextOptions `json:"windowsOptions,omitempty"`
+	ProcMount                *string                        `json:"procMount,omitempty"`
 }
 
 type Capabilities struct {
@@ -122,9 +257,32 @@ type Capabilities struct {
 }
 
 type Volume struct {
-	Name     string                `json:"name"`
-	HostPath *HostPathVolumeSource `json:"hostPath,omitempty"`
-	Secret   *SecretVolumeSource   `json:"secret,omitempty"`
+	Name      string                 `json:"name"`
+	HostPath  *HostPathVolumeSource  `json:"hostPath,omitempty"`
+	Secret    *SecretVolumeSource    `json:"secret,omitempty"`
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+	Projected *ProjectedVolumeSource `json:"projected,omitempty"`
+}
+
+type ConfigMapVolumeSource struct {
+	Name string `json:"name"`
+}
+
+type ProjectedVolumeSource struct {
+	Sources []VolumeProjection `json:"sources"`
+}
+
+type VolumeProjection struct {
+	Secret    *SecretProjection    `json:"secret,omitempty"`
+	ConfigMap *ConfigMapProjection `json:"configMap,omitempty"`
+}
+
+type SecretProjection struct {
+	Name string `json:"name"`
+}
+
+type ConfigMapProjection struct {
+	Name string `json:"name"`
 }
 
 type HostPathVolumeSource struct {
@@ -139,9 +297,10 @@ type SecretVolumeSource struct {
 // RBAC
 
 type PolicyRule struct {
-	APIGroups []string `json:"apiGroups,omitempty"`
-	Resources []string `json:"resources,omitempty"`
-	Verbs     []string `json:"verbs,omitempty"`
+	APIGroups     []string `json:"apiGroups,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Verbs         []string `json:"verbs,omitempty"`
 }
 
 type Role struct {
@@ -200,14 +359,48 @@ type ClusterRoleBindingList struct {
 // NetworkPolicy
 
 type LabelSelector struct {
-	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
 }
 
 type NetworkPolicySpec struct {
-	PodSelector LabelSelector `json:"podSelector"`
-	PolicyTypes []string      `json:"policyTypes,omitempty"`
-	Ingress     []any         `json:"ingress,omitempty"`
-	Egress      []any         `json:"egress,omitempty"`
+	PodSelector LabelSelector              `json:"podSelector"`
+	PolicyTypes []string                   `json:"policyTypes,omitempty"`
+	Ingress     []NetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress      []NetworkPolicyEgressRule  `json:"egress,omitempty"`
+}
+
+type NetworkPolicyIngressRule struct {
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	From  []NetworkPolicyPeer `json:"from,omitempty"`
+}
+
+type NetworkPolicyEgressRule struct {
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	To    []NetworkPolicyPeer `json:"to,omitempty"`
+}
+
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `json:"podSelector,omitempty"`
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty"`
+	IPBlock           *IPBlock       `json:"ipBlock,omitempty"`
+}
+
+type IPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+type NetworkPolicyPort struct {
+	Protocol string       `json:"protocol,omitempty"`
+	Port     *IntOrString `json:"port,omitempty"`
+	EndPort  *int32       `json:"endPort,omitempty"`
 }
 
 type NetworkPolicy struct {
@@ -223,19 +416,41 @@ type NetworkPolicyList struct {
 // Service
 
 type ServicePort struct {
-	Port     int32  `json:"port"`
-	NodePort int32  `json:"nodePort,omitempty"`
-	Protocol string `json:"protocol"`
+	Name       string      `json:"name,omitempty"`
+	Port       int32       `json:"port"`
+	TargetPort IntOrString `json:"targetPort,omitempty"`
+	NodePort   int32       `json:"nodePort,omitempty"`
+	Protocol   string      `json:"protocol"`
 }
 
 type ServiceSpec struct {
-	Type  string        `json:"type,omitempty"`
-	Ports []ServicePort `json:"ports,omitempty"`
+	Type                     string            `json:"type,omitempty"`
+	Selector                 map[string]string `json:"selector,omitempty"`
+	Ports                    []ServicePort     `json:"ports,omitempty"`
+	ClusterIP                string            `json:"clusterIP,omitempty"`
+	ExternalIPs              []string          `json:"externalIPs,omitempty"`
+	LoadBalancerSourceRanges []string          `json:"loadBalancerSourceRanges,omitempty"`
+	ExternalName             string            `json:"externalName,omitempty"`
+	ExternalTrafficPolicy    string            `json:"externalTrafficPolicy,omitempty"`
+}
+
+type ServiceStatus struct {
+	LoadBalancer LoadBalancerStatus `json:"loadBalancer,omitempty"`
+}
+
+type LoadBalancerStatus struct {
+	Ingress []LoadBalancerIngress `json:"ingress,omitempty"`
+}
+
+type LoadBalancerIngress struct {
+	IP       string `json:"ip,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
 }
 
 type Service struct {
-	Metadata ObjectMeta  `json:"metadata"`
-	Spec     ServiceSpec `json:"spec"`
+	Metadata ObjectMeta    `json:"metadata"`
+	Spec     ServiceSpec   `json:"spec"`
+	Status   ServiceStatus `json:"status,omitempty"`
 }
 
 type ServiceList struct {
@@ -243,10 +458,123 @@ type ServiceList struct {
 	Metadata ListMeta  `json:"metadata"`
 }
 
+// Secret (read only with -analyze-secrets)
+
+type Secret struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Type     string            `json:"type,omitempty"`
+	Data     map[string][]byte `json:"data,omitempty"`
+}
+
+type SecretList struct {
+	Items    []Secret `json:"items"`
+	Metadata ListMeta `json:"metadata"`
+}
+
+// ConfigMap
+
+type ConfigMap struct {
+	Metadata   ObjectMeta        `json:"metadata"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+}
+
+type ConfigMapList struct {
+	Items    []ConfigMap `json:"items"`
+	Metadata ListMeta    `json:"metadata"`
+}
+
+// LimitRange and ResourceQuota
+
+type LimitRange struct {
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     LimitRangeSpec `json:"spec"`
+}
+
+type LimitRangeSpec struct {
+	Limits []LimitRangeItem `json:"limits"`
+}
+
+type LimitRangeItem struct {
+	Type           string       `json:"type"`
+	Max            ResourceList `json:"max,omitempty"`
+	Min            ResourceList `json:"min,omitempty"`
+	Default        ResourceList `json:"default,omitempty"`
+	DefaultRequest ResourceList `json:"defaultRequest,omitempty"`
+}
+
+type LimitRangeList struct {
+	Items    []LimitRange `json:"items"`
+	Metadata ListMeta     `json:"metadata"`
+}
+
+type ResourceQuota struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Spec     ResourceQuotaSpec `json:"spec"`
+}
+
+type ResourceQuotaSpec struct {
+	Hard ResourceList `json:"hard,omitempty"`
+}
+
+type ResourceQuotaList struct {
+	Items    []ResourceQuota `json:"items"`
+	Metadata ListMeta        `json:"metadata"`
+}
+
 // Ingress
 
 type Ingress struct {
-	Metadata ObjectMeta `json:"metadata"`
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     IngressSpec `json:"spec"`
+}
+
+type IngressSpec struct {
+	IngressClassName *string         `json:"ingressClassName,omitempty"`
+	DefaultBackend   *IngressBackend `json:"defaultBackend,omitempty"`
+	TLS              []IngressTLS    `json:"tls,omitempty"`
+	Rules            []IngressRule   `json:"rules,omitempty"`
+}
+
+type IngressTLS struct {
+	Hosts      []string `json:"hosts,omitempty"`
+	SecretName string   `json:"secretName,omitempty"`
+}
+
+type IngressRule struct {
+	Host string                `json:"host,omitempty"`
+	HTTP *HTTPIngressRuleValue `json:"http,omitempty"`
+}
+
+type HTTPIngressRuleValue struct {
+	Paths []HTTPIngressPath `json:"paths"`
+}
+
+type HTTPIngressPath struct {
+	Path     string         `json:"path,omitempty"`
+	PathType string         `json:"pathType,omitempty"`
+	Backend  IngressBackend `json:"backend"`
+}
+
+type IngressBackend struct {
+	Service  *IngressServiceBackend `json:"service,omitempty"`
+	Resource *TypedObjectReference  `json:"resource,omitempty"`
+}
+
+type IngressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ServiceBackendPort `json:"port,omitempty"`
+}
+
+type ServiceBackendPort struct {
+	Name   string `json:"name,omitempty"`
+	Number int32  `json:"number,omitempty"`
+}
+
+type TypedObjectReference struct {
+	APIGroup *string `json:"apiGroup,omitempty"`
+	Kind     string  `json:"kind"`
+	Name     string  `json:"name"`
 }
 
 type IngressList struct {
@@ -254,13 +582,390 @@ type IngressList struct {
 	Metadata ListMeta  `json:"metadata"`
 }
 
+// Gateway API (gateway.networking.k8s.io)
+
+type Gateway struct {
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     GatewaySpec `json:"spec"`
+}
+
+type GatewaySpec struct {
+	GatewayClassName string     `json:"gatewayClassName"`
+	Listeners        []Listener `json:"listeners"`
+}
+
+type Listener struct {
+	Name          string         `json:"name"`
+	Hostname      *string        `json:"hostname,omitempty"`
+	Port          int32          `json:"port"`
+	Protocol      string         `json:"protocol"`
+	TLS           *GatewayTLS    `json:"tls,omitempty"`
+	AllowedRoutes *AllowedRoutes `json:"allowedRoutes,omitempty"`
+}
+
+type GatewayTLS struct {
+	Mode            string             `json:"mode,omitempty"`
+	CertificateRefs []GatewayObjectRef `json:"certificateRefs,omitempty"`
+}
+
+type AllowedRoutes struct {
+	Namespaces *RouteNamespaces `json:"namespaces,omitempty"`
+}
+
+type RouteNamespaces struct {
+	From     string         `json:"from,omitempty"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+// GatewayObjectRef covers parentRefs, backendRefs and certificateRefs.
+type GatewayObjectRef struct {
+	Group       string `json:"group,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	SectionName string `json:"sectionName,omitempty"`
+	Port        int32  `json:"port,omitempty"`
+}
+
+type HTTPRoute struct {
+	Metadata ObjectMeta    `json:"metadata"`
+	Spec     HTTPRouteSpec `json:"spec"`
+}
+
+type HTTPRouteSpec struct {
+	ParentRefs []GatewayObjectRef `json:"parentRefs,omitempty"`
+	Hostnames  []string           `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule    `json:"rules,omitempty"`
+}
+
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch   `json:"matches,omitempty"`
+	BackendRefs []GatewayObjectRef `json:"backendRefs,omitempty"`
+}
+
+type HTTPRouteMatch struct {
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+type HTTPPathMatch struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type TLSRoute struct {
+	Metadata ObjectMeta   `json:"metadata"`
+	Spec     TLSRouteSpec `json:"spec"`
+}
+
+type TLSRouteSpec struct {
+	ParentRefs []GatewayObjectRef `json:"parentRefs,omitempty"`
+	Hostnames  []string           `json:"hostnames,omitempty"`
+	Rules      []TLSRouteRule     `json:"rules,omitempty"`
+}
+
+type TLSRouteRule struct {
+	BackendRefs []GatewayObjectRef `json:"backendRefs,omitempty"`
+}
+
+type ReferenceGrant struct {
+	Metadata ObjectMeta         `json:"metadata"`
+	Spec     ReferenceGrantSpec `json:"spec"`
+}
+
+type ReferenceGrantSpec struct {
+	From []ReferenceGrantFrom `json:"from"`
+	To   []ReferenceGrantTo   `json:"to"`
+}
+
+type ReferenceGrantFrom struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+}
+
+type ReferenceGrantTo struct {
+	Group string  `json:"group"`
+	Kind  string  `json:"kind"`
+	Name  *string `json:"name,omitempty"`
+}
+
+// Cilium (cilium.io/v2): CiliumNetworkPolicy and CiliumClusterwideNetworkPolicy
+
+type CiliumNetworkPolicy struct {
+	Metadata ObjectMeta   `json:"metadata"`
+	Spec     *CiliumRule  `json:"spec,omitempty"`
+	Specs    []CiliumRule `json:"specs,omitempty"`
+}
+
+type CiliumRule struct {
+	EndpointSelector  *LabelSelector           `json:"endpointSelector,omitempty"`
+	NodeSelector      *LabelSelector           `json:"nodeSelector,omitempty"`
+	Ingress           []CiliumPolicyRule       `json:"ingress,omitempty"`
+	IngressDeny       []CiliumPolicyRule       `json:"ingressDeny,omitempty"`
+	Egress            []CiliumPolicyRule       `json:"egress,omitempty"`
+	EgressDeny        []CiliumPolicyRule       `json:"egressDeny,omitempty"`
+	EnableDefaultDeny *CiliumDefaultDenyConfig `json:"enableDefaultDeny,omitempty"`
+}
+
+type CiliumPolicyRule struct {
+	FromEndpoints []LabelSelector `json:"fromEndpoints,omitempty"`
+	FromEntities  []string        `json:"fromEntities,omitempty"`
+	FromCIDR      []string        `json:"fromCIDR,omitempty"`
+	ToEndpoints   []LabelSelector `json:"toEndpoints,omitempty"`
+	ToEntities    []string        `json:"toEntities,omitempty"`
+	ToCIDR        []string        `json:"toCIDR,omitempty"`
+	ToFQDNs       []any           `json:"toFQDNs,omitempty"`
+	ToPorts       []any           `json:"toPorts,omitempty"`
+}
+
+type CiliumDefaultDenyConfig struct {
+	Ingress *bool `json:"ingress,omitempty"`
+	Egress  *bool `json:"egress,omitempty"`
+}
+
+// Calico (projectcalico.org/v3 or crd.projectcalico.org/v1): NetworkPolicy and GlobalNetworkPolicy
+
+type CalicoNetworkPolicy struct {
+	Metadata ObjectMeta       `json:"metadata"`
+	Spec     CalicoPolicySpec `json:"spec"`
+}
+
+type CalicoPolicySpec struct {
+	Tier              string       `json:"tier,omitempty"`
+	Order             *float64     `json:"order,omitempty"`
+	Selector          string       `json:"selector,omitempty"`
+	NamespaceSelector string       `json:"namespaceSelector,omitempty"`
+	Types             []string     `json:"types,omitempty"`
+	Ingress           []CalicoRule `json:"ingress,omitempty"`
+	Egress            []CalicoRule `json:"egress,omitempty"`
+}
+
+type CalicoRule struct {
+	Action      string           `json:"action"`
+	Protocol    *IntOrString     `json:"protocol,omitempty"`
+	Source      CalicoEntityRule `json:"source,omitempty"`
+	Destination CalicoEntityRule `json:"destination,omitempty"`
+}
+
+type CalicoEntityRule struct {
+	Nets              []string      `json:"nets,omitempty"`
+	NotNets           []string      `json:"notNets,omitempty"`
+	Selector          string        `json:"selector,omitempty"`
+	NamespaceSelector string        `json:"namespaceSelector,omitempty"`
+	Ports             []IntOrString `json:"ports,omitempty"`
+	Domains           []string      `json:"domains,omitempty"`
+}
+
+// Istio (security.istio.io)
+
+type PeerAuthentication struct {
+	Metadata ObjectMeta             `json:"metadata"`
+	Spec     PeerAuthenticationSpec `json:"spec"`
+}
+
+type PeerAuthenticationSpec struct {
+	Selector      *WorkloadSelector    `json:"selector,omitempty"`
+	MTLS          *IstioMTLS           `json:"mtls,omitempty"`
+	PortLevelMTLS map[string]IstioMTLS `json:"portLevelMtls,omitempty"`
+}
+
+type WorkloadSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+type IstioMTLS struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+type IstioAuthorizationPolicy struct {
+	Metadata ObjectMeta                   `json:"metadata"`
+	Spec     IstioAuthorizationPolicySpec `json:"spec"`
+}
+
+type IstioAuthorizationPolicySpec struct {
+	Selector *WorkloadSelector `json:"selector,omitempty"`
+	Action   string            `json:"action,omitempty"`
+	Rules    []IstioRule       `json:"rules,omitempty"`
+}
+
+type IstioRule struct {
+	From []IstioRuleFrom `json:"from,omitempty"`
+	To   []any           `json:"to,omitempty"`
+	When []any           `json:"when,omitempty"`
+}
+
+type IstioRuleFrom struct {
+	Source IstioSource `json:"source"`
+}
+
+type IstioSource struct {
+	Principals           []string `json:"principals,omitempty"`
+	NotPrincipals        []string `json:"notPrincipals,omitempty"`
+	RequestPrincipals    []string `json:"requestPrincipals,omitempty"`
+	NotRequestPrincipals []string `json:"notRequestPrincipals,omitempty"`
+	Namespaces           []string `json:"namespaces,omitempty"`
+	NotNamespaces        []string `json:"notNamespaces,omitempty"`
+	IPBlocks             []string `json:"ipBlocks,omitempty"`
+	NotIPBlocks          []string `json:"notIpBlocks,omitempty"`
+	RemoteIPBlocks       []string `json:"remoteIpBlocks,omitempty"`
+	NotRemoteIPBlocks    []string `json:"notRemoteIpBlocks,omitempty"`
+}
+
+// Linkerd (policy.linkerd.io)
+
+type LinkerdServer struct {
+	Metadata ObjectMeta        `json:"metadata"`
+	Spec     LinkerdServerSpec `json:"spec"`
+}
+
+type LinkerdServerSpec struct {
+	PodSelector   *LabelSelector `json:"podSelector,omitempty"`
+	Port          IntOrString    `json:"port"`
+	ProxyProtocol string         `json:"proxyProtocol,omitempty"`
+	AccessPolicy  string         `json:"accessPolicy,omitempty"`
+}
+
+type LinkerdAuthorizationPolicy struct {
+	Metadata ObjectMeta                     `json:"metadata"`
+	Spec     LinkerdAuthorizationPolicySpec `json:"spec"`
+}
+
+type LinkerdAuthorizationPolicySpec struct {
+	TargetRef                  GatewayObjectRef   `json:"targetRef"`
+	RequiredAuthenticationRefs []GatewayObjectRef `json:"requiredAuthenticationRefs"`
+}
+
+type LinkerdServerAuthorization struct {
+	Metadata ObjectMeta                     `json:"metadata"`
+	Spec     LinkerdServerAuthorizationSpec `json:"spec"`
+}
+
+type LinkerdServerAuthorizationSpec struct {
+	Server LinkerdServerRef    `json:"server"`
+	Client LinkerdClientPolicy `json:"client"`
+}
+
+type LinkerdServerRef struct {
+	Name     string         `json:"name,omitempty"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+type LinkerdClientPolicy struct {
+	Unauthenticated bool             `json:"unauthenticated,omitempty"`
+	Networks        []LinkerdNetwork `json:"networks,omitempty"`
+	MeshTLS         *LinkerdMeshTLS  `json:"meshTLS,omitempty"`
+}
+
+type LinkerdNetwork struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+type LinkerdMeshTLS struct {
+	UnauthenticatedTLS bool     `json:"unauthenticatedTLS,omitempty"`
+	Identities         []string `json:"identities,omitempty"`
+}
+
+type LinkerdNetworkAuthentication struct {
+	Metadata ObjectMeta                       `json:"metadata"`
+	Spec     LinkerdNetworkAuthenticationSpec `json:"spec"`
+}
+
+type LinkerdNetworkAuthenticationSpec struct {
+	Networks []LinkerdNetwork `json:"networks"`
+}
+
+type LinkerdMeshTLSAuthentication struct {
+	Metadata ObjectMeta                       `json:"metadata"`
+	Spec     LinkerdMeshTLSAuthenticationSpec `json:"spec"`
+}
+
+type LinkerdMeshTLSAuthenticationSpec struct {
+	Identities   []string           `json:"identities,omitempty"`
+	IdentityRefs []GatewayObjectRef `json:"identityRefs,omitempty"`
+}
+
 // Node
 
 type Node struct {
 	Metadata ObjectMeta `json:"metadata"`
+	Spec     NodeSpec   `json:"spec,omitempty"`
+	Status   NodeStatus `json:"status,omitempty"`
+}
+
+type NodeSpec struct {
+	PodCIDR       string   `json:"podCIDR,omitempty"`
+	PodCIDRs      []string `json:"podCIDRs,omitempty"`
+	Unschedulable bool     `json:"unschedulable,omitempty"`
+	Taints        []Taint  `json:"taints,omitempty"`
+}
+
+type NodeStatus struct {
+	Conditions []NodeCondition `json:"conditions,omitempty"`
+	Addresses  []NodeAddress   `json:"addresses,omitempty"`
+	NodeInfo   NodeSystemInfo  `json:"nodeInfo,omitempty"`
+}
+
+type NodeCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type NodeAddress struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+type NodeSystemInfo struct {
+	KernelVersion           string `json:"kernelVersion"`
+	OSImage                 string `json:"osImage"`
+	ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+	KubeletVersion          string `json:"kubeletVersion"`
+	KubeProxyVersion        string `json:"kubeProxyVersion,omitempty"`
+	OperatingSystem         string `json:"operatingSystem"`
+	Architecture            string `json:"architecture"`
+}
+
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
 }
 
 type NodeList struct {
 	Items    []Node   `json:"items"`
 	Metadata ListMeta `json:"metadata"`
 }
+
+// Kubelet configuration (/api/v1/nodes/{name}/proxy/configz, read only with -kubelet-config)
+
+type KubeletConfiguration struct {
+	Authentication        KubeletAuthentication `json:"authentication"`
+	Authorization         KubeletAuthorization  `json:"authorization"`
+	ReadOnlyPort          int32                 `json:"readOnlyPort,omitempty"`
+	ProtectKernelDefaults bool                  `json:"protectKernelDefaults,omitempty"`
+	SeccompDefault        bool                  `json:"seccompDefault,omitempty"`
+	RotateCertificates    bool                  `json:"rotateCertificates,omitempty"`
+	ServerTLSBootstrap    bool                  `json:"serverTLSBootstrap,omitempty"`
+	EventRecordQPS        *int32                `json:"eventRecordQPS,omitempty"`
+	TLSCipherSuites       []string              `json:"tlsCipherSuites,omitempty"`
+	TLSMinVersion         string                `json:"tlsMinVersion,omitempty"`
+}
+
+type KubeletAuthentication struct {
+	X509 struct {
+		ClientCAFile string `json:"clientCAFile,omitempty"`
+	} `json:"x509"`
+	Webhook struct {
+		Enabled *bool `json:"enabled,omitempty"`
+	} `json:"webhook"`
+	Anonymous struct {
+		Enabled *bool `json:"enabled,omitempty"`
+	} `json:"anonymous"`
+}
+
+type KubeletAuthorization struct {
+	Mode string `json:"mode,omitempty"`
+}
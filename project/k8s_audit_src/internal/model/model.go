@@ -53,10 +53,24 @@ type Finding struct {
 // Report is a machine-readable output.
 type Report struct {
 	Cluster     ClusterMeta       `json:"cluster"`
+	Node        string            `json:"node,omitempty"` // set by the node agent
 	GeneratedAt time.Time         `json:"generatedAt"`
 	Summary     map[Severity]int  `json:"summary"`
 	Findings    []Finding         `json:"findings"`
 	Notes       map[string]string `json:"notes,omitempty"`
+	// SecretExposure is the per-Secret blast radius, highest score first.
+	SecretExposure []SecretExposure `json:"secretExposure,omitempty"`
+}
+
+// SecretExposure lists who can obtain a Secret's value and how.
+type SecretExposure struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type,omitempty"`
+	Score     int      `json:"score"`
+	Readers   []string `json:"readers,omitempty"`   // RBAC subjects with get/list/watch
+	Consumers []string `json:"consumers,omitempty"` // pods and objects referencing the Secret
+	ExecVia   []string `json:"execVia,omitempty"`   // subjects and pods able to exec into consumers
 }
 
 type ClusterMeta struct {
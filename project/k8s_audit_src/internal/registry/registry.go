@@ -0,0 +1,225 @@
+// Package registry is a minimal read-only OCI distribution client: manifest
+// and blob fetches with anonymous bearer-token auth.
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/k8s-audit/internal/imageref"
+)
+
+var ErrNotFound = errors.New("not found")
+
+const manifestAccept = "application/vnd.oci.image.manifest.v1+json, " +
+	"application/vnd.oci.image.index.v1+json, " +
+	"application/vnd.docker.distribution.manifest.v2+json, " +
+	"application/vnd.docker.distribution.manifest.list.v2+json"
+
+type Options struct {
+	// Mirror, if set, is queried instead of the image's own registry (host[:port]).
+	Mirror    string
+	PlainHTTP bool
+	Timeout   time.Duration
+}
+
+type Client struct {
+	hc   *http.Client
+	opts Options
+
+	mu     sync.Mutex
+	tokens map[string]string // host/repository -> bearer token
+}
+
+func New(opts Options) *Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = 15 * time.Second
+	}
+	return &Client{hc: &http.Client{Timeout: opts.Timeout}, opts: opts, tokens: map[string]string{}}
+}
+
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type Manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"` // image index / manifest list
+}
+
+// Manifest fetches a manifest by tag or digest and returns it with its digest.
+func (c *Client) Manifest(ref imageref.Ref, reference string) (*Manifest, string, error) {
+	body, hdr, err := c.get(ref, "/manifests/"+reference, manifestAccept)
+	if err != nil {
+		return nil, "", err
+	}
+	var m Manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, "", fmt.Errorf("decode manifest %s: %w", reference, err)
+	}
+	digest := hdr.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return &m, digest, nil
+}
+
+// Resolve returns the current manifest digest of a tag.
+func (c *Client) Resolve(ref imageref.Ref, tag string) (string, error) {
+	_, digest, err := c.Manifest(ref, tag)
+	return digest, err
+}
+
+// Blob fetches a blob and checks its digest.
+func (c *Client) Blob(ref imageref.Ref, digest string) ([]byte, error) {
+	body, _, err := c.get(ref, "/blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	if alg, want, ok := strings.Cut(digest, ":"); ok && alg == "sha256" {
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("blob %s: digest mismatch", digest)
+		}
+	}
+	return body, nil
+}
+
+func (c *Client) host(ref imageref.Ref) string {
+	if c.opts.Mirror != "" {
+		return c.opts.Mirror
+	}
+	if ref.Registry == imageref.DefaultRegistry {
+		return "registry-1.docker.io"
+	}
+	return ref.Registry
+}
+
+func (c *Client) get(ref imageref.Ref, suffix, accept string) ([]byte, http.Header, error) {
+	host := c.host(ref)
+	scheme := "https"
+	if c.opts.PlainHTTP {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s%s", scheme, host, ref.Repository, suffix)
+	key := host + "/" + ref.Repository
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.mu.Lock()
+		tok := c.tokens[key]
+		c.mu.Unlock()
+		if tok != "" {
+			req.Header.Set("Authorization", "Bearer "+tok)
+		}
+		resp, err := c.hc.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && attempt == 0:
+			tok, err := c.fetchToken(resp.Header.Get("WWW-Authenticate"))
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: auth: %w", u, err)
+			}
+			c.mu.Lock()
+			c.tokens[key] = tok
+			c.mu.Unlock()
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, nil, fmt.Errorf("%s: %w", u, ErrNotFound)
+		case resp.StatusCode < 200 || resp.StatusCode >= 300:
+			return nil, nil, fmt.Errorf("%s: status %d", u, resp.StatusCode)
+		}
+		return body, resp.Header, nil
+	}
+	return nil, nil, fmt.Errorf("%s: unauthorized", u)
+}
+
+// fetchToken performs the anonymous Docker token flow for a Bearer challenge.
+func (c *Client) fetchToken(challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported challenge %q", challenge)
+	}
+	p := parseChallenge(params)
+	if p["realm"] == "" {
+		return "", fmt.Errorf("no realm in challenge %q", challenge)
+	}
+	q := url.Values{}
+	if p["service"] != "" {
+		q.Set("service", p["service"])
+	}
+	if p["scope"] != "" {
+		q.Set("scope", p["scope"])
+	}
+	resp, err := c.hc.Get(p["realm"] + "?" + q.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint status %d", resp.StatusCode)
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", err
+	}
+	if t.Token != "" {
+		return t.Token, nil
+	}
+	return t.AccessToken, nil
+}
+
+// parseChallenge parses key="value" pairs of a WWW-Authenticate header.
+func parseChallenge(s string) map[string]string {
+	out := map[string]string{}
+	for s != "" {
+		s = strings.TrimLeft(s, ", ")
+		k, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		var v string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				v, rest = rest[1:], ""
+			} else {
+				v, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			v, rest, _ = strings.Cut(rest, ",")
+		}
+		out[strings.ToLower(strings.TrimSpace(k))] = v
+		s = rest
+	}
+	return out
+}
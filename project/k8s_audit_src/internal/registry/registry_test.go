@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/imageref"
+)
+
+const testManifest = `{"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"digest":"sha256:c0"},"layers":[{"digest":"sha256:l1","size":3}]}`
+
+func sha(b string) string {
+	sum := sha256.Sum256([]byte(b))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// fakeRegistry serves testManifest for app:v1 (with Docker-Content-Digest) and app:v2
+// (without it), and one blob. With token set, /v2 requires the anonymous token flow.
+func fakeRegistry(t *testing.T, token string) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	auth := func(w http.ResponseWriter, r *http.Request) bool {
+		if token == "" || r.Header.Get("Authorization") == "Bearer "+token {
+			return true
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="http://`+r.Host+`/token",service="fake",scope="repository:team/app:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:team/app:pull" || r.URL.Query().Get("service") != "fake" {
+			t.Errorf("token request %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"access_token":"` + token + `"}`))
+	})
+	mux.HandleFunc("/v2/team/app/", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/v2/team/app") {
+		case "/manifests/v1":
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("manifest Accept = %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:from-header")
+			w.Write([]byte(testManifest))
+		case "/manifests/v2":
+			w.Write([]byte(testManifest))
+		case "/blobs/" + sha("ok"):
+			w.Write([]byte("ok"))
+		case "/blobs/" + sha("other"):
+			w.Write([]byte("tampered"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return mux
+}
+
+func TestManifestAndResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		tls   bool
+	}{
+		{"plain http", "", false},
+		{"plain http with token", "secret", false},
+		{"https", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			if tt.tls {
+				srv = httptest.NewTLSServer(fakeRegistry(t, tt.token))
+			} else {
+				srv = httptest.NewServer(fakeRegistry(t, tt.token))
+			}
+			defer srv.Close()
+			c := New(Options{Mirror: strings.TrimPrefix(strings.TrimPrefix(srv.URL, "http://"), "https://"), PlainHTTP: !tt.tls})
+			if tt.tls {
+				c.hc = srv.Client()
+			}
+			ref := imageref.Parse("registry.example/team/app:v1")
+
+			m, digest, err := c.Manifest(ref, "v1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if digest != "sha256:from-header" || len(m.Layers) != 1 || m.Config.Digest != "sha256:c0" {
+				t.Errorf("Manifest() = %+v, %q", m, digest)
+			}
+			// without Docker-Content-Digest the digest is computed from the body
+			if d, err := c.Resolve(ref, "v2"); err != nil || d != sha(testManifest) {
+				t.Errorf("Resolve(v2) = %q, %v, want %q", d, err, sha(testManifest))
+			}
+			if _, err := c.Resolve(ref, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Resolve(missing) error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestPlainHTTPAgainstTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(fakeRegistry(t, ""))
+	defer srv.Close()
+	c := New(Options{Mirror: strings.TrimPrefix(srv.URL, "https://"), PlainHTTP: true})
+	if _, err := c.Resolve(imageref.Parse("team/app"), "v1"); err == nil {
+		t.Error("plain HTTP request to a TLS registry succeeded")
+	}
+}
+
+func TestBlob(t *testing.T) {
+	srv := httptest.NewServer(fakeRegistry(t, ""))
+	defer srv.Close()
+	c := New(Options{Mirror: strings.TrimPrefix(srv.URL, "http://"), PlainHTTP: true})
+	ref := imageref.Parse("registry.example/team/app")
+
+	if b, err := c.Blob(ref, sha("ok")); err != nil || string(b) != "ok" {
+		t.Errorf("Blob() = %q, %v", b, err)
+	}
+	if _, err := c.Blob(ref, sha("other")); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("tampered blob: err = %v, want digest mismatch", err)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	got := parseChallenge(`realm="https://auth.example/token",service="registry.example",scope="repository:a/b:pull,push"`)
+	want := map[string]string{"realm": "https://auth.example/token", "service": "registry.example", "scope": "repository:a/b:pull,push"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
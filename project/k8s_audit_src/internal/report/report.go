@@ -25,8 +25,13 @@ func Summarize(findings []model.Finding) map[model.Severity]int {
 }
 
 func PrintTextReport(r model.Report) {
-	fmt.Printf("k8s-audit (in-cluster)\n")
-	fmt.Printf("API Server: %s\n", r.Cluster.APIServer)
+	if r.Node != "" {
+		fmt.Printf("k8s-audit (node agent)\n")
+		fmt.Printf("Node: %s\n", r.Node)
+	} else {
+		fmt.Printf("k8s-audit (in-cluster)\n")
+		fmt.Printf("API Server: %s\n", r.Cluster.APIServer)
+	}
 	if r.Cluster.ServerVersion != "" {
 		fmt.Printf("Kubernetes: %s\n", r.Cluster.ServerVersion)
 	}
@@ -62,6 +67,17 @@ func PrintTextReport(r model.Report) {
 		fmt.Println()
 	}
 
+	if len(r.SecretExposure) > 0 {
+		fmt.Println("Secret exposure (top 10):")
+		for i, e := range r.SecretExposure {
+			if i == 10 {
+				break
+			}
+			fmt.Printf("- %s/%s score=%d readers=%d consumers=%d exec=%d\n", e.Namespace, e.Name, e.Score, len(e.Readers), len(e.Consumers), len(e.ExecVia))
+		}
+		fmt.Println()
+	}
+
 	if len(r.Notes) > 0 {
 		fmt.Println("Notes:")
 		keys := make([]string, 0, len(r.Notes))
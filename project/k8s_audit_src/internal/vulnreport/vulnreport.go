@@ -0,0 +1,425 @@
+// Package vulnreport imports offline image scan results (Trivy, Grype,
+// CycloneDX, SPDX) and indexes them by image reference and digest.
+package vulnreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"example.com/k8s-audit/internal/imageref"
+	"example.com/k8s-audit/internal/model"
+)
+
+type Vuln struct {
+	ID        string
+	Package   string
+	Installed string
+	Fixed     string
+	Severity  model.Severity
+	CVSS      float64
+}
+
+// ImageReport is the scan result for one image.
+type ImageReport struct {
+	Source  string // file the report came from
+	Format  string
+	Image   string
+	Digests []string
+	Vulns   []Vuln
+}
+
+// MaxSeverity returns the highest vulnerability severity ("" if none).
+func (r *ImageReport) MaxSeverity() model.Severity {
+	var max model.Severity
+	for _, v := range r.Vulns {
+		if model.SeverityRank(v.Severity) > model.SeverityRank(max) {
+			max = v.Severity
+		}
+	}
+	return max
+}
+
+// Index maps image references and digests to reports.
+type Index struct {
+	byDigest map[string]*ImageReport
+	byRef    map[string]*ImageReport
+	Reports  []*ImageReport
+}
+
+func (idx *Index) add(r *ImageReport) {
+	sortVulns(r.Vulns)
+	idx.Reports = append(idx.Reports, r)
+	for _, d := range r.Digests {
+		if d != "" {
+			idx.byDigest[d] = r
+		}
+	}
+	if r.Image != "" {
+		ref := imageref.Parse(r.Image)
+		idx.byRef[ref.String()] = r
+		if ref.Digest != "" {
+			idx.byDigest[ref.Digest] = r
+		}
+	}
+}
+
+// Lookup finds the report for a running container, preferring the digest.
+func (idx *Index) Lookup(image, digest string) *ImageReport {
+	if idx == nil {
+		return nil
+	}
+	if digest != "" {
+		if r, ok := idx.byDigest[digest]; ok {
+			return r
+		}
+	}
+	ref := imageref.Parse(image)
+	if ref.Digest != "" {
+		if r, ok := idx.byDigest[ref.Digest]; ok {
+			return r
+		}
+		ref.Digest = ""
+	}
+	return idx.byRef[ref.String()]
+}
+
+// Load reads report files; directories are scanned for *.json.
+func Load(paths []string) (*Index, error) {
+	idx := &Index{byDigest: map[string]*ImageReport{}, byRef: map[string]*ImageReport{}}
+	for _, p := range paths {
+		st, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{p}
+		if st.IsDir() {
+			files, err = filepath.Glob(filepath.Join(p, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			r, err := Parse(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			r.Source = f
+			idx.add(r)
+		}
+	}
+	return idx, nil
+}
+
+// Parse detects the report format and decodes it.
+func Parse(b []byte) (*ImageReport, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, err
+	}
+	switch {
+	case probe["Results"] != nil || probe["ArtifactName"] != nil:
+		return parseTrivy(b)
+	case probe["matches"] != nil:
+		return parseGrype(b)
+	case probe["bomFormat"] != nil:
+		return parseCycloneDX(b)
+	case probe["spdxVersion"] != nil:
+		return parseSPDX(b)
+	}
+	return nil, fmt.Errorf("unknown report format")
+}
+
+// Trivy (trivy image -f json)
+
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Metadata     struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			CVSS             map[string]struct {
+				V3Score float64 `json:"V3Score"`
+			} `json:"CVSS"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+func parseTrivy(b []byte) (*ImageReport, error) {
+	var t trivyReport
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	r := &ImageReport{Format: "trivy", Image: t.ArtifactName}
+	for _, d := range t.Metadata.RepoDigests {
+		r.Digests = append(r.Digests, imageref.Parse(d).Digest)
+	}
+	for _, res := range t.Results {
+		for _, v := range res.Vulnerabilities {
+			score := 0.0
+			for _, c := range v.CVSS {
+				if c.V3Score > score {
+					score = c.V3Score
+				}
+			}
+			r.Vulns = append(r.Vulns, Vuln{
+				ID:        v.VulnerabilityID,
+				Package:   v.PkgName,
+				Installed: v.InstalledVersion,
+				Fixed:     v.FixedVersion,
+				Severity:  severity(v.Severity),
+				CVSS:      score,
+			})
+		}
+	}
+	return r, nil
+}
+
+// Grype (grype -o json)
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+			CVSS []struct {
+				Metrics struct {
+					BaseScore float64 `json:"baseScore"`
+				} `json:"metrics"`
+			} `json:"cvss"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Target struct {
+			UserInput      string   `json:"userInput"`
+			RepoDigests    []string `json:"repoDigests"`
+			ManifestDigest string   `json:"manifestDigest"`
+		} `json:"target"`
+	} `json:"source"`
+}
+
+func parseGrype(b []byte) (*ImageReport, error) {
+	var g grypeReport
+	if err := json.Unmarshal(b, &g); err != nil {
+		return nil, err
+	}
+	tgt := g.Source.Target
+	r := &ImageReport{Format: "grype", Image: tgt.UserInput}
+	for _, d := range tgt.RepoDigests {
+		r.Digests = append(r.Digests, imageref.Parse(d).Digest)
+	}
+	if tgt.ManifestDigest != "" {
+		r.Digests = append(r.Digests, tgt.ManifestDigest)
+	}
+	for _, m := range g.Matches {
+		score := 0.0
+		for _, c := range m.Vulnerability.CVSS {
+			if c.Metrics.BaseScore > score {
+				score = c.Metrics.BaseScore
+			}
+		}
+		r.Vulns = append(r.Vulns, Vuln{
+			ID:        m.Vulnerability.ID,
+			Package:   m.Artifact.Name,
+			Installed: m.Artifact.Version,
+			Fixed:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:  severity(m.Vulnerability.Severity),
+			CVSS:      score,
+		})
+	}
+	return r, nil
+}
+
+// CycloneDX JSON (with the vulnerabilities extension)
+
+type cdxBOM struct {
+	Metadata struct {
+		Component cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components      []cdxComponent `json:"components"`
+	Vulnerabilities []struct {
+		ID      string `json:"id"`
+		Ratings []struct {
+			Severity string  `json:"severity"`
+			Score    float64 `json:"score"`
+		} `json:"ratings"`
+		Affects        []cdxAffect `json:"affects"`
+		Recommendation string      `json:"recommendation"`
+	} `json:"vulnerabilities"`
+}
+
+type cdxAffect struct {
+	Ref string `json:"ref"`
+}
+
+type cdxComponent struct {
+	BOMRef     string `json:"bom-ref"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	PURL       string `json:"purl"`
+	Properties []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"properties"`
+}
+
+func parseCycloneDX(b []byte) (*ImageReport, error) {
+	var bom cdxBOM
+	if err := json.Unmarshal(b, &bom); err != nil {
+		return nil, err
+	}
+	mc := bom.Metadata.Component
+	r := &ImageReport{Format: "cyclonedx", Image: mc.Name}
+	if d := purlDigest(mc.PURL); d != "" {
+		r.Digests = append(r.Digests, d)
+	}
+	if strings.HasPrefix(mc.Version, "sha256:") {
+		r.Digests = append(r.Digests, mc.Version)
+	} else if mc.Version != "" && !strings.Contains(mc.Name, ":") {
+		r.Image = mc.Name + ":" + mc.Version
+	}
+	for _, p := range mc.Properties {
+		if strings.HasSuffix(p.Name, "RepoDigest") {
+			r.Digests = append(r.Digests, imageref.Parse(p.Value).Digest)
+		}
+	}
+	comps := map[string]cdxComponent{}
+	for _, c := range bom.Components {
+		comps[c.BOMRef] = c
+	}
+	for _, v := range bom.Vulnerabilities {
+		var sev model.Severity
+		score := 0.0
+		for _, rt := range v.Ratings {
+			if s := severity(rt.Severity); model.SeverityRank(s) > model.SeverityRank(sev) {
+				sev = s
+			}
+			if rt.Score > score {
+				score = rt.Score
+			}
+		}
+		affects := v.Affects
+		if len(affects) == 0 {
+			affects = []cdxAffect{{}}
+		}
+		for _, a := range affects {
+			c := comps[a.Ref]
+			r.Vulns = append(r.Vulns, Vuln{
+				ID:        v.ID,
+				Package:   c.Name,
+				Installed: c.Version,
+				Fixed:     v.Recommendation,
+				Severity:  sev,
+				CVSS:      score,
+			})
+		}
+	}
+	return r, nil
+}
+
+// SPDX JSON: vulnerabilities only via SECURITY/advisory external refs.
+
+type spdxDocument struct {
+	Name     string `json:"name"`
+	Packages []struct {
+		Name                  string `json:"name"`
+		VersionInfo           string `json:"versionInfo"`
+		PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+		ExternalRefs          []struct {
+			ReferenceCategory string `json:"referenceCategory"`
+			ReferenceType     string `json:"referenceType"`
+			ReferenceLocator  string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+var vulnIDPattern = regexp.MustCompile(`(CVE-\d{4}-\d{4,}|GHSA-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{4})`)
+
+func parseSPDX(b []byte) (*ImageReport, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	r := &ImageReport{Format: "spdx", Image: doc.Name}
+	for _, p := range doc.Packages {
+		for _, ref := range p.ExternalRefs {
+			switch {
+			case p.PrimaryPackagePurpose == "CONTAINER" && ref.ReferenceType == "purl":
+				if d := purlDigest(ref.ReferenceLocator); d != "" {
+					r.Digests = append(r.Digests, d)
+				}
+				if r.Image == "" || strings.HasPrefix(r.Image, "sbom") {
+					r.Image = p.Name + ":" + p.VersionInfo
+				}
+			case strings.EqualFold(ref.ReferenceCategory, "SECURITY") && ref.ReferenceType == "advisory":
+				if id := vulnIDPattern.FindString(ref.ReferenceLocator); id != "" {
+					r.Vulns = append(r.Vulns, Vuln{ID: id, Package: p.Name, Installed: p.VersionInfo, Severity: model.SeverityMedium})
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// purlDigest extracts the digest from pkg:oci/name@sha256%3A...?...
+func purlDigest(purl string) string {
+	if !strings.HasPrefix(purl, "pkg:oci/") && !strings.HasPrefix(purl, "pkg:docker/") {
+		return ""
+	}
+	i := strings.Index(purl, "@")
+	if i < 0 {
+		return ""
+	}
+	v := purl[i+1:]
+	if j := strings.IndexAny(v, "?#"); j >= 0 {
+		v = v[:j]
+	}
+	v, _ = url.PathUnescape(v)
+	if !strings.HasPrefix(v, "sha256:") {
+		return ""
+	}
+	return v
+}
+
+func severity(s string) model.Severity {
+	sev, err := model.ParseSeverity(s)
+	if err != nil {
+		return "" // negligible/unknown
+	}
+	return sev
+}
+
+func sortVulns(v []Vuln) {
+	sort.SliceStable(v, func(i, j int) bool {
+		a, b := model.SeverityRank(v[i].Severity), model.SeverityRank(v[j].Severity)
+		if a != b {
+			return a > b
+		}
+		if v[i].CVSS != v[j].CVSS {
+			return v[i].CVSS > v[j].CVSS
+		}
+		return v[i].ID < v[j].ID
+	})
+}
@@ -0,0 +1,123 @@
+package vulnreport
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"example.com/k8s-audit/internal/model"
+)
+
+func digest(c string) string {
+	return "sha256:" + strings.Repeat(c, 64)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file    string
+		format  string
+		image   string
+		digests []string
+		vulns   int
+		first   Vuln
+	}{
+		{
+			file: "trivy.json", format: "trivy", image: "nginx:1.25.3", digests: []string{digest("a")}, vulns: 4,
+			first: Vuln{ID: "CVE-2023-44487", Package: "libnghttp2-14", Installed: "1.52.0-1", Fixed: "1.52.0-1+deb12u1", Severity: model.SeverityHigh, CVSS: 7.5},
+		},
+		{
+			file: "grype.json", format: "grype", image: "ghcr.io/acme/api:2.1.0", digests: []string{digest("b"), digest("c")}, vulns: 2,
+			first: Vuln{ID: "GHSA-xvch-5gv4-984h", Package: "minimist", Installed: "1.2.5", Fixed: "1.2.6, 2.0.0", Severity: model.SeverityCritical, CVSS: 9.8},
+		},
+		{
+			file: "cyclonedx.json", format: "cyclonedx", image: "registry.example/team/worker:3.4", digests: []string{digest("d")}, vulns: 1,
+			first: Vuln{ID: "CVE-2023-5678", Package: "openssl", Installed: "3.0.11-1", Fixed: "3.0.13-1~deb12u1", Severity: model.SeverityMedium, CVSS: 5.3},
+		},
+		{
+			file: "spdx.json", format: "spdx", image: "redis:7.2.3", digests: []string{digest("e")}, vulns: 1,
+			first: Vuln{ID: "CVE-2023-6129", Package: "libssl3", Installed: "3.1.4-r1", Severity: model.SeverityMedium},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Format != tt.format || r.Image != tt.image || !reflect.DeepEqual(r.Digests, tt.digests) {
+				t.Errorf("got format=%s image=%q digests=%v, want %s %q %v", r.Format, r.Image, r.Digests, tt.format, tt.image, tt.digests)
+			}
+			if len(r.Vulns) != tt.vulns {
+				t.Fatalf("got %d vulns, want %d: %+v", len(r.Vulns), tt.vulns, r.Vulns)
+			}
+			if r.Vulns[0] != tt.first {
+				t.Errorf("first vuln = %+v, want %+v", r.Vulns[0], tt.first)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`{"foo": 1}`)); err == nil {
+		t.Error("unknown format: want error")
+	}
+}
+
+func TestLoadAndLookup(t *testing.T) {
+	idx, err := Load([]string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Reports) != 4 {
+		t.Fatalf("loaded %d reports, want 4", len(idx.Reports))
+	}
+
+	tests := []struct {
+		name          string
+		image, digest string
+		want          string // report format, "" for no report
+	}{
+		{"tag", "nginx:1.25.3", "", "trivy"},
+		{"normalised tag", "docker.io/library/nginx:1.25.3", "", "trivy"},
+		{"running digest wins over tag", "nginx:latest", digest("a"), "trivy"},
+		{"repo digest", "ghcr.io/acme/api:latest", digest("b"), "grype"},
+		{"manifest digest in the image reference", "ghcr.io/acme/api@" + digest("c"), "", "grype"},
+		{"cyclonedx tag", "registry.example/team/worker:3.4", "", "cyclonedx"},
+		{"spdx purl digest", "redis:7", digest("e"), "spdx"},
+		{"other tag", "nginx:1.25.4", "", ""},
+		{"not scanned", "busybox:1.36", digest("f"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := idx.Lookup(tt.image, tt.digest)
+			switch {
+			case tt.want == "" && r != nil:
+				t.Errorf("Lookup(%q, %q) = %s report, want none", tt.image, tt.digest, r.Format)
+			case tt.want != "" && (r == nil || r.Format != tt.want):
+				t.Errorf("Lookup(%q, %q) = %v, want %s report", tt.image, tt.digest, r, tt.want)
+			}
+		})
+	}
+
+	// Load sorts vulnerabilities by severity, then CVSS; unknown severities go last
+	r := idx.Lookup("nginx:1.25.3", "")
+	var ids []string
+	for _, v := range r.Vulns {
+		ids = append(ids, v.ID)
+	}
+	if want := []string{"CVE-2024-0001", "CVE-2023-44487", "CVE-2011-3374", "CVE-2005-2541"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("vuln order = %v, want %v", ids, want)
+	}
+	if r.MaxSeverity() != model.SeverityCritical || r.Source != filepath.Join("testdata", "trivy.json") {
+		t.Errorf("MaxSeverity = %s, Source = %s", r.MaxSeverity(), r.Source)
+	}
+
+	var nilIdx *Index
+	if nilIdx.Lookup("nginx:1.25.3", "") != nil {
+		t.Error("nil index must not return a report")
+	}
+}